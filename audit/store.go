@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/imrenagi/calendly-demo/jsonl"
+)
+
+// Store keeps the entries. Entries can only be appended, never changed or
+// removed
+type Store interface {
+	Append(e Entry) error
+
+	// Query returns the entries matching the filter in the order they were
+	// appended
+	Query(f Filter) ([]Entry, error)
+}
+
+// FileStore appends the entries to a file, one JSON object per line
+type FileStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileStore) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return jsonl.Append(s.Path, e)
+}
+
+// Query scans the file. A missing file has no entries
+func (s *FileStore) Query(filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	err := jsonl.Read(s.Path, func(line []byte) error {
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		if filter.Matches(e) {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// MemoryStore keeps the entries in memory
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (s *MemoryStore) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.Changes = append([]Change(nil), e.Changes...)
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *MemoryStore) Query(filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for _, e := range s.entries {
+		if filter.Matches(e) {
+			e.Changes = append([]Change(nil), e.Changes...)
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,124 @@
+// Package audit records who changed events and bookings
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Entry is a change of an event or a booking
+type Entry struct {
+	ID     uuid.UUID `json:"id"`
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+
+	EventID uuid.UUID `json:"event_id"`
+	// BookingID is nil for changes of the event's settings
+	BookingID uuid.UUID `json:"booking_id"`
+
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Change is the value of a field before and after the change
+type Change struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Filter selects entries. Zero fields match every entry
+type Filter struct {
+	EventID   uuid.UUID
+	BookingID uuid.UUID
+	Actor     string
+
+	// From and To limit the time of the change, To is exclusive
+	From, To time.Time
+}
+
+// Matches returns true if the entry is selected by the filter
+func (f Filter) Matches(e Entry) bool {
+	switch {
+	case f.EventID != uuid.Nil && f.EventID != e.EventID:
+		return false
+	case f.BookingID != uuid.Nil && f.BookingID != e.BookingID:
+		return false
+	case f.Actor != "" && f.Actor != e.Actor:
+		return false
+	case !f.From.IsZero() && e.At.Before(f.From):
+		return false
+	case !f.To.IsZero() && !e.At.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// Trail records the domain events of core in its store. Subscribe it to the
+// bus, e.g. bus.Subscribe("audit", "", trail.Handle)
+type Trail struct {
+	Store Store
+}
+
+// Handle appends the entry of the domain event. Domain events which are not
+// changes are ignored
+func (t Trail) Handle(d core.DomainEvent) error {
+	entry, ok := NewEntry(d)
+	if !ok {
+		return nil
+	}
+	if err := t.Store.Append(entry); err != nil {
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	return nil
+}
+
+// Query returns the entries matching the filter, oldest first
+func (t Trail) Query(f Filter) ([]Entry, error) {
+	return t.Store.Query(f)
+}
+
+// NewEntry returns the audit entry of the domain event. Bookings created
+// without actor are attributed to their invitee
+func NewEntry(d core.DomainEvent) (Entry, bool) {
+	entry := Entry{
+		ID:     uuid.New(),
+		At:     d.OccurredAt(),
+		Action: d.Topic(),
+	}
+	var changes []core.FieldChange
+	switch d := d.(type) {
+	case core.BookingCreatedEvent:
+		entry.EventID, entry.BookingID, entry.Actor = d.EventID, d.Booking.ID, d.Actor
+		if entry.Actor == "" {
+			entry.Actor = d.Booking.Invitee.Email
+		}
+		changes = core.DiffBookings(core.Booking{}, d.Booking)
+	case core.BookingRescheduledEvent:
+		entry.EventID, entry.BookingID, entry.Actor = d.EventID, d.Booking.ID, d.Actor
+		changes = core.DiffBookings(d.Previous, d.Booking)
+	case core.BookingCancelledEvent:
+		entry.EventID, entry.BookingID, entry.Actor = d.EventID, d.Booking.ID, d.Actor
+		changes = core.DiffBookings(d.Previous, d.Booking)
+	case core.BookingApprovedEvent:
+		entry.EventID, entry.BookingID, entry.Actor = d.EventID, d.Booking.ID, d.Actor
+		changes = core.DiffBookings(d.Previous, d.Booking)
+	case core.BookingDeclinedEvent:
+		entry.EventID, entry.BookingID, entry.Actor = d.EventID, d.Booking.ID, d.Actor
+		changes = core.DiffBookings(d.Previous, d.Booking)
+	case core.EventUpdatedEvent:
+		entry.EventID, entry.Actor = d.EventID, d.Actor
+		changes = d.Changes
+	default:
+		return Entry{}, false
+	}
+
+	for _, c := range changes {
+		entry.Changes = append(entry.Changes, Change{Field: c.Field, Before: c.Before, After: c.After})
+	}
+	return entry, true
+}
@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+func TestTrail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	trail := Trail{Store: &FileStore{Path: filepath.Join(dir, "audit.log")}}
+	bus := eventbus.New(&eventbus.MemoryOutbox{})
+	bus.Subscribe("audit", "", trail.Handle)
+
+	e := &core.Event{
+		ID:       uuid.New(),
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]core.Range{
+			time.Monday: []core.Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Publisher:   bus,
+	}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Email: "bar@foo.com"},
+		StartTime: slot,
+	})
+	assert.NoError(t, err)
+	other, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Email: "baz@foo.com"},
+		StartTime: slot.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	err = e.As("host@example.com", func(e *core.Event) error {
+		e.Update(func(e *core.Event) { e.MaxInvitees = 2 })
+		_, err := e.CancelBooking(b.ID)
+		return err
+	})
+	assert.NoError(t, err)
+
+	entries, err := trail.Query(Filter{BookingID: b.ID})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "booking.created", entries[0].Action)
+	assert.Equal(t, "bar@foo.com", entries[0].Actor)
+	assert.Equal(t, []Change{
+		{Field: "status", After: "confirmed"},
+		{Field: "start_time", After: "2022-02-07T00:00:00Z"},
+	}, entries[0].Changes)
+	assert.Equal(t, "booking.cancelled", entries[1].Action)
+	assert.Equal(t, "host@example.com", entries[1].Actor)
+	assert.Equal(t, []Change{{Field: "status", Before: "confirmed", After: "cancelled"}}, entries[1].Changes)
+
+	entries, err = trail.Query(Filter{Actor: "host@example.com"})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "event.updated", entries[0].Action)
+	assert.Equal(t, uuid.Nil, entries[0].BookingID)
+	assert.Equal(t, []Change{{Field: "max_invitees", Before: "1", After: "2"}}, entries[0].Changes)
+
+	entries, err = trail.Query(Filter{EventID: e.ID})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, other.ID, entries[1].BookingID)
+
+	entries, err = trail.Query(Filter{EventID: uuid.New()})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFilter_Matches(t *testing.T) {
+	eventID, bookingID := uuid.New(), uuid.New()
+	at := time.Date(2022, 2, 8, 10, 0, 0, 0, time.UTC)
+	entry := Entry{At: at, Actor: "host@example.com", EventID: eventID, BookingID: bookingID}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "should match empty filter", filter: Filter{}, want: true},
+		{name: "should match event", filter: Filter{EventID: eventID}, want: true},
+		{name: "should not match other event", filter: Filter{EventID: uuid.New()}, want: false},
+		{name: "should not match other booking", filter: Filter{EventID: eventID, BookingID: uuid.New()}, want: false},
+		{name: "should not match other actor", filter: Filter{Actor: "bar@foo.com"}, want: false},
+		{
+			name:   "should match the day",
+			filter: Filter{From: time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC), To: time.Date(2022, 2, 9, 0, 0, 0, 0, time.UTC)},
+			want:   true,
+		},
+		{name: "should exclude the end", filter: Filter{To: at}, want: false},
+		{name: "should not match before from", filter: Filter{From: at.Add(time.Second)}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(entry))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Command replay prints the bookings of an event as they were at a given
+// time, rebuilt from the ledger files
+//
+//	replay -dir ./ledger -event 6f1c... -at 2022-02-07T09:00:00Z
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/ledger"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the ledger files")
+	event := flag.String("event", "", "id of the event")
+	at := flag.String("at", "", "time to replay up to in RFC 3339, e.g. 2022-02-07T09:00:00Z. Empty replays every record")
+	records := flag.Bool("records", false, "print the records instead of the bookings")
+	flag.Parse()
+
+	if err := run(*dir, *event, *at, *records); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, event, at string, records bool) error {
+	eventID, err := uuid.Parse(event)
+	if err != nil {
+		return fmt.Errorf("invalid event id %q: %w", event, err)
+	}
+	var until time.Time
+	if at != "" {
+		if until, err = time.Parse(time.RFC3339, at); err != nil {
+			return fmt.Errorf("invalid time %q: %w", at, err)
+		}
+	}
+
+	store := &ledger.FileStore{Dir: dir}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if records {
+		rs, err := store.Records(eventID, 0)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "SEQ\tAT\tTYPE\tBOOKING\tSTART\tACTOR")
+		for _, r := range rs {
+			if !until.IsZero() && r.At.After(until) {
+				continue
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Seq, r.At.Format(time.RFC3339), r.Type,
+				r.Booking.ID, r.Booking.StartTime.Format(time.RFC3339), r.Actor)
+		}
+		return nil
+	}
+
+	bookings, err := ledger.Ledger{Store: store}.BookingsAt(eventID, until)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "BOOKING\tSTATUS\tSTART\tINVITEE\tSEQUENCE")
+	for _, b := range bookings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", b.ID, b.Status, b.StartTime.Format(time.RFC3339),
+			b.Invitee.Email, b.Sequence)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package conference
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// HTTP creates meetings through a JSON API:
+//
+//	POST   {Endpoint}/meetings       creates a meeting
+//	PUT    {Endpoint}/meetings/{id}  updates the meeting
+//	DELETE {Endpoint}/meetings/{id}  deletes the meeting
+//
+// Create and update respond with the meeting, e.g.
+// {"id": "123", "join_url": "https://...", "dial_ins": [{"country": "ID",
+// "number": "+6221...", "pin": "1234"}]}
+type HTTP struct {
+	Endpoint string
+
+	// Token is sent as bearer token if set
+	Token string
+
+	// Client calls the provider, with DefaultTimeout if it is nil
+	Client *http.Client
+}
+
+const DefaultTimeout = 10 * time.Second
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+type meetingRequest struct {
+	BookingID    string    `json:"booking_id"`
+	Topic        string    `json:"topic"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	HostEmail    string    `json:"host_email,omitempty"`
+	InviteeEmail string    `json:"invitee_email"`
+}
+
+type meetingResponse struct {
+	ID      string `json:"id"`
+	JoinURL string `json:"join_url"`
+	DialIns []struct {
+		Country string `json:"country"`
+		Number  string `json:"number"`
+		PIN     string `json:"pin"`
+	} `json:"dial_ins"`
+}
+
+func (p HTTP) Create(e core.Event, b core.Booking) (core.Conference, error) {
+	return p.send(http.MethodPost, "/meetings", newMeetingRequest(e, b))
+}
+
+func (p HTTP) Update(e core.Event, b core.Booking) (core.Conference, error) {
+	return p.send(http.MethodPut, "/meetings/"+url.PathEscape(b.Conference.ID), newMeetingRequest(e, b))
+}
+
+// Delete deletes the meeting. A meeting which does not exist anymore is not
+// an error
+func (p HTTP) Delete(e core.Event, b core.Booking) error {
+	resp, err := p.do(http.MethodDelete, "/meetings/"+url.PathEscape(b.Conference.ID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkStatus(resp)
+}
+
+func newMeetingRequest(e core.Event, b core.Booking) *meetingRequest {
+	r := &meetingRequest{
+		BookingID:    b.ID.String(),
+		Topic:        e.Name,
+		Start:        b.StartTime.UTC(),
+		End:          b.StartTime.Add(e.Duration).UTC(),
+		InviteeEmail: b.Invitee.Email,
+	}
+	if h := e.BookingHost(b); h != nil {
+		r.HostEmail = h.Email
+	}
+	return r
+}
+
+func (p HTTP) send(method, path string, body *meetingRequest) (core.Conference, error) {
+	resp, err := p.do(method, path, body)
+	if err != nil {
+		return core.Conference{}, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return core.Conference{}, err
+	}
+
+	var m meetingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return core.Conference{}, fmt.Errorf("invalid meeting response: %w", err)
+	}
+	if m.ID == "" || m.JoinURL == "" {
+		return core.Conference{}, fmt.Errorf("invalid meeting response: missing id or join url")
+	}
+
+	c := core.Conference{ID: m.ID, JoinURL: m.JoinURL}
+	for _, d := range m.DialIns {
+		c.DialIns = append(c.DialIns, core.DialIn{Country: d.Country, Number: d.Number, PIN: d.PIN})
+	}
+	return c, nil
+}
+
+func (p HTTP) do(method, path string, body interface{}) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(p.Endpoint, "/")+path, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = defaultClient
+	}
+	return client.Do(req)
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("conference provider responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,163 @@
+package conference
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+)
+
+// fakeMeetingServer is an in-memory stand-in of a conferencing API
+type fakeMeetingServer struct {
+	mu       sync.Mutex
+	meetings map[string]meetingRequest
+	nextID   int
+	requests []string
+}
+
+func (s *fakeMeetingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/meetings/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/meetings":
+		s.nextID++
+		id = strconv.Itoa(s.nextID)
+	case r.Method == http.MethodDelete:
+		if _, ok := s.meetings[id]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(s.meetings, id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case r.Method == http.MethodPut:
+		if _, ok := s.meetings[id]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var m meetingRequest
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.meetings[id] = m
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       id,
+		"join_url": "https://video.example.com/j/" + id + "?t=" + m.Start.Format("150405"),
+		"dial_ins": []map[string]string{{"country": "US", "number": "+15555550100", "pin": "42" + id}},
+	})
+}
+
+func newFakeMeetingServer(t *testing.T) (*fakeMeetingServer, HTTP) {
+	fake := &fakeMeetingServer{meetings: map[string]meetingRequest{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return fake, HTTP{Endpoint: srv.URL + "/api/", Token: "secret", Client: srv.Client()}
+}
+
+func TestHTTP_BookingLifecycle(t *testing.T) {
+	fake, p := newFakeMeetingServer(t)
+	e := coretest.NewEvent(30 * time.Minute)
+	e.Conferencing = p
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Email: "bar@foo.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", b.Conference.ID)
+	assert.Equal(t, "https://video.example.com/j/1?t=090000", b.Conference.JoinURL)
+	assert.Equal(t, []core.DialIn{{Country: "US", Number: "+15555550100", PIN: "421"}}, b.Conference.DialIns)
+	assert.Equal(t, core.MeetingLocation{Kind: core.VideoLink, Value: b.Conference.JoinURL}, b.MeetingLocation)
+	assert.Equal(t, meetingRequest{
+		BookingID:    b.ID.String(),
+		Topic:        "Chat",
+		Start:        time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		End:          time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC),
+		HostEmail:    "foo@bar.com",
+		InviteeEmail: "bar@foo.com",
+	}, fake.meetings["1"])
+
+	b, err = e.RescheduleBooking(b.ID, time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://video.example.com/j/1?t=093000", b.MeetingLocation.Value)
+	assert.Equal(t, time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC), fake.meetings["1"].Start)
+
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, fake.meetings)
+
+	assert.Equal(t, []string{"POST /api/meetings", "PUT /api/meetings/1", "DELETE /api/meetings/1"}, fake.requests)
+}
+
+func TestHTTP_Errors(t *testing.T) {
+	_, p := newFakeMeetingServer(t)
+	p.Token = "wrong"
+	e := coretest.NewEvent(30 * time.Minute)
+	e.Conferencing = p
+
+	_, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Email: "bar@foo.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Empty(t, e.Bookings)
+}
+
+func TestHTTP_Delete_NotFound(t *testing.T) {
+	_, p := newFakeMeetingServer(t)
+	err := p.Delete(core.Event{}, core.Booking{Conference: core.Conference{ID: "404"}})
+	assert.NoError(t, err)
+}
+
+func TestHTTP_RoundRobinHost(t *testing.T) {
+	fake, p := newFakeMeetingServer(t)
+	e := &core.Event{
+		Name:     "Demo",
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]core.Range{
+			time.Monday: []core.Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+		},
+		Location:     time.UTC,
+		MaxInvitees:  1,
+		Kind:         core.RoundRobin,
+		Conferencing: p,
+	}
+	e.AddTeamMember(core.NewHost("Foo", "foo@bar.com"))
+	e.AddTeamMember(core.NewHost("Baz", "baz@bar.com"))
+	e.Bookings = core.Bookings{{ID: uuid.New(), StartTime: time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC), HostID: e.Hosts[0].ID, CreatedAt: time.Now()}}
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Email: "bar@foo.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, e.Hosts[1].ID, b.HostID)
+	assert.Equal(t, "baz@bar.com", fake.meetings["1"].HostEmail)
+}
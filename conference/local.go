@@ -0,0 +1,52 @@
+// Package conference provides conferencing providers generating the online
+// meeting of bookings
+package conference
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Local derives the meeting from the booking id, e.g. for a self-hosted
+// meeting server whose rooms are created on first join. The same booking
+// always gets the same meeting
+type Local struct {
+	// BaseURL is the prefix of join URLs, e.g. https://meet.example.com
+	BaseURL string
+
+	// DialIns are the phone numbers of the meeting server. Each meeting
+	// gets its own PIN
+	DialIns []core.DialIn
+}
+
+func (l Local) Create(e core.Event, b core.Booking) (core.Conference, error) {
+	id := b.ID.String()
+	c := core.Conference{
+		ID:      id,
+		JoinURL: strings.TrimSuffix(l.BaseURL, "/") + "/" + id,
+	}
+	for _, d := range l.DialIns {
+		d.PIN = pin(id)
+		c.DialIns = append(c.DialIns, d)
+	}
+	return c, nil
+}
+
+// Update returns the same meeting since it does not depend on the time
+func (l Local) Update(e core.Event, b core.Booking) (core.Conference, error) {
+	return l.Create(e, b)
+}
+
+func (l Local) Delete(e core.Event, b core.Booking) error {
+	return nil
+}
+
+// pin returns a 6 digits PIN derived from the meeting id
+func pin(id string) string {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return fmt.Sprintf("%06d", h.Sum32()%1000000)
+}
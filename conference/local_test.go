@@ -0,0 +1,37 @@
+package conference
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+func TestLocal(t *testing.T) {
+	p := Local{
+		BaseURL: "https://meet.example.com/",
+		DialIns: []core.DialIn{{Country: "ID", Number: "+62215550100"}},
+	}
+	b := core.Booking{ID: uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")}
+
+	c, err := p.Create(core.Event{}, b)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://meet.example.com/6ba7b810-9dad-11d1-80b4-00c04fd430c8", c.JoinURL)
+	assert.Len(t, c.DialIns, 1)
+	assert.Len(t, c.DialIns[0].PIN, 6)
+
+	// the meeting does not change with the booking time
+	b.Conference = c
+	b.StartTime = time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	updated, err := p.Update(core.Event{}, b)
+	assert.NoError(t, err)
+	assert.Equal(t, c, updated)
+
+	other, _ := p.Create(core.Event{}, core.Booking{ID: uuid.New()})
+	assert.NotEqual(t, c.JoinURL, other.JoinURL)
+
+	assert.NoError(t, p.Delete(core.Event{}, b))
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBookingDeclined   = fmt.Errorf("booking has been declined")
+	ErrBookingNotPending = fmt.Errorf("booking is not pending")
+	ErrBookingExpired    = fmt.Errorf("pending booking has expired")
+)
+
+// newBooking returns a booking which is pending if the event requires
+// confirmation
+func (e Event) newBooking(params CreateBookingParameters) *Booking {
+	b := NewBooking(e.clock(), params)
+	if e.RequiresConfirmation {
+		b.Status = BookingPending
+		if e.ConfirmationWindow > 0 {
+			b.ExpiresAt = b.CreatedAt.Add(e.ConfirmationWindow)
+		}
+	}
+	return b
+}
+
+// ApproveBooking confirms a pending booking
+func (e *Event) ApproveBooking(id uuid.UUID) (*Booking, error) {
+	i, err := e.findPending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := e.Now()
+	if !e.Bookings[i].ExpiresAt.IsZero() && !now.Before(e.Bookings[i].ExpiresAt) {
+		if _, err := e.ExpirePendingBookings(now); err != nil {
+			return nil, err
+		}
+		return nil, ErrBookingExpired
+	}
+
+	previous := e.Bookings[i]
+	b := previous
+	b.transition(BookingConfirmed, now, "")
+	b.ExpiresAt = time.Time{}
+	b.Sequence++
+	if err := e.journal(BookingChange{Kind: ChangeApproved, Booking: b, Previous: previous}); err != nil {
+		return nil, err
+	}
+	e.Bookings[i] = b
+	e.notifyChange(ChangeApproved, b, previous)
+	return &b, nil
+}
+
+// DeclineBooking rejects a pending booking and releases its seat
+func (e *Event) DeclineBooking(id uuid.UUID, reason string) (*Booking, error) {
+	i, err := e.findPending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := e.Bookings[i]
+	now := e.Now()
+	b := previous
+	b.transition(BookingDeclined, now, reason)
+	b.Sequence++
+	if err := e.journal(BookingChange{Kind: ChangeDeclined, Booking: b, Previous: previous}); err != nil {
+		return nil, err
+	}
+	e.discardConference(previous)
+	e.Bookings[i] = b
+	e.notifyChange(ChangeDeclined, b, previous)
+	e.promoteWaitlist(b.StartTime, now)
+	return &b, nil
+}
+
+// ExpirePendingBookings declines pending bookings which were not approved in
+// time and returns them. Their seats are already free once the window passed,
+// this records the decline and notifies it. Bookings which can not be
+// journaled stay pending for the next call.
+//
+// Nothing expires bookings in the background since an Event is not safe for
+// concurrent use: the caller owning the event drives expiry, e.g. by calling
+// it with the event's Clock on a ticker
+func (e *Event) ExpirePendingBookings(now time.Time) (Bookings, error) {
+	var expired Bookings
+	for i, previous := range e.Bookings {
+		if previous.Status != BookingPending || previous.ExpiresAt.IsZero() || now.Before(previous.ExpiresAt) {
+			continue
+		}
+		b := previous
+		b.transition(BookingDeclined, now, "expired")
+		b.Sequence++
+		if err := e.journal(BookingChange{Kind: ChangeDeclined, Booking: b, Previous: previous}); err != nil {
+			return expired, err
+		}
+		e.discardConference(previous)
+		e.Bookings[i] = b
+		expired = append(expired, b)
+		e.notifyChange(ChangeDeclined, b, previous)
+		e.promoteWaitlist(b.StartTime, now)
+	}
+	return expired, nil
+}
+
+// PendingBookings returns bookings waiting for the host's approval. Bookings
+// whose confirmation window passed are left out, even before
+// ExpirePendingBookings declines them
+func (e Event) PendingBookings() Bookings {
+	var pending Bookings
+	for _, b := range e.Bookings.holding(e.Now()) {
+		if b.Status == BookingPending {
+			pending = append(pending, b)
+		}
+	}
+	return pending
+}
+
+func (e Event) findPending(id uuid.UUID) (int, error) {
+	i := e.Bookings.Find(id)
+	if i < 0 {
+		return -1, ErrBookingNotFound
+	}
+	if e.Bookings[i].Status != BookingPending {
+		return -1, ErrBookingNotPending
+	}
+	return i, nil
+}
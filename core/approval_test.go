@@ -0,0 +1,197 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/clocktest"
+)
+
+func newApprovalEvent(window time.Duration) *Event {
+	return &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 3600}},
+		},
+		Location:             time.UTC,
+		MaxInvitees:          1,
+		RequiresConfirmation: true,
+		ConfirmationWindow:   window,
+	}
+}
+
+func TestEvent_CreateBooking_RequiresConfirmation(t *testing.T) {
+	e := newApprovalEvent(time.Hour)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	assert.Equal(t, BookingPending, b.Status)
+	assert.Equal(t, b.CreatedAt.Add(time.Hour), b.ExpiresAt)
+	assert.Len(t, e.PendingBookings(), 1)
+
+	// pending booking holds the seat
+	_, err = e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.Equal(t, ErrTimeNotAvailable, err)
+}
+
+func TestEvent_ApproveBooking(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     time.Duration
+		prepare    func(e *Event, id uuid.UUID)
+		id         func(id uuid.UUID) uuid.UUID
+		wantErr    error
+		wantStatus BookingStatus
+	}{
+		{
+			name:       "should confirm pending booking",
+			window:     time.Hour,
+			wantStatus: BookingConfirmed,
+		},
+		{
+			name:       "should confirm pending booking without expiry",
+			wantStatus: BookingConfirmed,
+		},
+		{
+			name:       "should decline expired booking",
+			window:     time.Hour,
+			prepare:    func(e *Event, id uuid.UUID) { e.Bookings[0].ExpiresAt = time.Now().Add(-time.Minute) },
+			wantErr:    ErrBookingExpired,
+			wantStatus: BookingDeclined,
+		},
+		{
+			name:   "should not approve declined booking",
+			window: time.Hour,
+			prepare: func(e *Event, id uuid.UUID) {
+				_, _ = e.DeclineBooking(id, "")
+			},
+			wantErr:    ErrBookingNotPending,
+			wantStatus: BookingDeclined,
+		},
+		{
+			name:       "should return error if booking is not found",
+			id:         func(uuid.UUID) uuid.UUID { return uuid.New() },
+			wantErr:    ErrBookingNotFound,
+			wantStatus: BookingPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newApprovalEvent(tt.window)
+			b, err := e.CreateBooking(CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+			assert.NoError(t, err)
+			if tt.prepare != nil {
+				tt.prepare(e, b.ID)
+			}
+			id := b.ID
+			if tt.id != nil {
+				id = tt.id(id)
+			}
+
+			got, err := e.ApproveBooking(id)
+			assert.True(t, errors.Is(err, tt.wantErr), "ApproveBooking() error = %v, wantErr %v", err, tt.wantErr)
+			assert.Equal(t, tt.wantStatus, e.Bookings[0].Status)
+			if tt.wantErr != nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.True(t, got.ExpiresAt.IsZero())
+			assert.Equal(t, 1, got.Sequence)
+			assert.Equal(t, BookingPending, got.Transitions[0].From)
+		})
+	}
+}
+
+func TestEvent_DeclineBooking(t *testing.T) {
+	e := newApprovalEvent(0)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+
+	got, err := e.DeclineBooking(b.ID, "fully booked that day")
+	assert.NoError(t, err)
+	assert.Equal(t, BookingDeclined, got.Status)
+	assert.Equal(t, []StatusTransition{{
+		From:   BookingPending,
+		To:     BookingDeclined,
+		At:     got.Transitions[0].At,
+		Reason: "fully booked that day",
+	}}, got.Transitions)
+
+	// declined booking releases the seat and can not be changed anymore
+	_, err = e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.Equal(t, ErrBookingDeclined, err)
+	_, err = e.DeclineBooking(b.ID, "")
+	assert.Equal(t, ErrBookingNotPending, err)
+}
+
+func TestEvent_ExpirePendingBookings(t *testing.T) {
+	e := newApprovalEvent(time.Hour)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+
+	expired, err := e.ExpirePendingBookings(b.CreatedAt.Add(59 * time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+	assert.Equal(t, BookingPending, e.Bookings[0].Status)
+
+	expired, err = e.ExpirePendingBookings(b.CreatedAt.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, BookingDeclined, expired[0].Status)
+	assert.Equal(t, "expired", expired[0].Transitions[0].Reason)
+	assert.Empty(t, e.PendingBookings())
+
+	spots, err := e.GetAvailableSpots(GetSpotParameters{Start: slot, End: slot.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, spots, 1)
+}
+
+func TestEvent_GetAvailableSpots_ExpiredPendingBooking(t *testing.T) {
+	clock := clocktest.NewFake(time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC))
+	e := newApprovalEvent(time.Hour)
+	e.Clock = clock
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+	_, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+
+	spots, err := e.GetAvailableSpots(GetSpotParameters{Start: slot, End: slot.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, spots)
+
+	assert.Len(t, e.PendingBookings(), 1)
+
+	// the seat is released once the window passed, without sweeping
+	clock.Advance(5 * time.Hour)
+	assert.Empty(t, e.PendingBookings())
+	assert.Equal(t, BookingPending, e.Bookings[0].Status)
+	spots, err = e.GetAvailableSpots(GetSpotParameters{Start: slot, End: slot.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, spots, 1)
+
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	assert.Equal(t, BookingPending, b.Status)
+}
+
+func TestEvent_CancelBooking_RecordsTransition(t *testing.T) {
+	e := newApprovalEvent(0)
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	got, err := e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+	assert.Len(t, got.Transitions, 1)
+	assert.Equal(t, BookingPending, got.Transitions[0].From)
+	assert.Equal(t, BookingCancelled, got.Transitions[0].To)
+	assert.Equal(t, got.CancelledAt, got.Transitions[0].At)
+}
@@ -6,19 +6,57 @@ import (
 	"github.com/google/uuid"
 )
 
-func NewBooking(p CreateBookingParameters) *Booking {
+// NewBooking returns a booking created at the current time of the clock
+func NewBooking(clock Clock, p CreateBookingParameters) *Booking {
 	return &Booking{
-		ID:        uuid.New(),
-		Invitee:   p.Invitee,
-		StartTime: p.StartTime,
-		CreatedAt: time.Now(),
+		ID:              uuid.New(),
+		Invitee:         p.Invitee,
+		StartTime:       p.StartTime,
+		CreatedAt:       clock.Now(),
+		Answers:         p.Answers,
+		MeetingLocation: p.MeetingLocation,
 	}
 }
 
 type Bookings []Booking
 
-func (b Bookings) IsAvailable(t time.Time) bool {
+// Active returns bookings which still take the time, i.e. not cancelled
+func (b Bookings) Active() Bookings {
+	var active Bookings
+	for _, booking := range b {
+		if booking.IsActive() {
+			active = append(active, booking)
+		}
+	}
+	return active
+}
+
+// holding returns the bookings except pending ones whose confirmation window
+// passed at now. They do not hold their seat anymore, even before
+// ExpirePendingBookings declines them
+func (b Bookings) holding(now time.Time) Bookings {
+	var holding Bookings
 	for _, booking := range b {
+		if booking.Status == BookingPending && !booking.ExpiresAt.IsZero() && !now.Before(booking.ExpiresAt) {
+			continue
+		}
+		holding = append(holding, booking)
+	}
+	return holding
+}
+
+// Find returns the index of booking with the given id or -1 if not found
+func (b Bookings) Find(id uuid.UUID) int {
+	for i, booking := range b {
+		if booking.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b Bookings) IsAvailable(t time.Time) bool {
+	for _, booking := range b.Active() {
 		if booking.StartTime.Equal(t) {
 			return false
 		}
@@ -29,7 +67,7 @@ func (b Bookings) IsAvailable(t time.Time) bool {
 // GetBookedCount returned the total spot has been booked for a given time
 func (b Bookings) GetBookedCount(t time.Time) int {
 	var count int
-	for _, booking := range b {
+	for _, booking := range b.Active() {
 		if booking.StartTime.Equal(t) {
 			count++
 		}
@@ -37,9 +75,111 @@ func (b Bookings) GetBookedCount(t time.Time) int {
 	return count
 }
 
+// Intervals returns the time taken by each booking given the event duration
+func (b Bookings) Intervals(d time.Duration) Intervals {
+	var intervals Intervals
+	for _, booking := range b.Active() {
+		intervals = append(intervals, Interval{
+			Start: booking.StartTime,
+			End:   booking.StartTime.Add(d),
+		})
+	}
+	return intervals
+}
+
+type BookingStatus int
+
+const (
+	BookingConfirmed BookingStatus = iota
+	BookingCancelled
+	// BookingPending is waiting for the host's approval. It holds the seat
+	// until it is approved, declined or expired
+	BookingPending
+	BookingDeclined
+)
+
+func (s BookingStatus) String() string {
+	switch s {
+	case BookingConfirmed:
+		return "confirmed"
+	case BookingCancelled:
+		return "cancelled"
+	case BookingPending:
+		return "pending"
+	case BookingDeclined:
+		return "declined"
+	default:
+		return "unknown"
+	}
+}
+
 type Booking struct {
 	ID        uuid.UUID
 	Invitee   Invitee
 	StartTime time.Time
 	CreatedAt time.Time
+
+	// Answers stores the invitee's answers to the event's questions
+	Answers Answers
+
+	// MeetingLocation is where the meeting happens
+	MeetingLocation MeetingLocation
+
+	// Conference is the online meeting created by the event's conferencing
+	// provider
+	Conference Conference
+
+	Status BookingStatus
+
+	// Sequence is the revision number of the booking. It is incremented
+	// every time the booking is rescheduled, cancelled, approved or declined
+	Sequence int
+
+	CancelledAt time.Time
+
+	// ExpiresAt is when a pending booking is declined if the host has not
+	// approved it. Zero means it never expires
+	ExpiresAt time.Time
+
+	// Transitions records every status change of the booking
+	Transitions []StatusTransition
+
+	// HostID is the host assigned to the booking of a team event. It is
+	// empty for single host event
+	HostID uuid.UUID
+
+	// SeriesID is set when the booking is an occurrence of a recurring series
+	SeriesID uuid.UUID
+}
+
+// StatusTransition is a change of booking status
+type StatusTransition struct {
+	From, To BookingStatus
+	At       time.Time
+	Reason   string
+}
+
+// IsActive returns true if the booking still takes the time
+func (b Booking) IsActive() bool {
+	return b.Status == BookingConfirmed || b.Status == BookingPending
+}
+
+// transition changes the status and records the change
+func (b *Booking) transition(to BookingStatus, at time.Time, reason string) {
+	b.Transitions = append(b.Transitions, StatusTransition{
+		From:   b.Status,
+		To:     to,
+		At:     at,
+		Reason: reason,
+	})
+	b.Status = to
+}
+
+// inactiveError returns the error of changing a booking which does not
+// take the time anymore
+func (b Booking) inactiveError() error {
+	if b.Status == BookingDeclined {
+		return ErrBookingDeclined
+	}
+	return ErrBookingCancelled
 }
@@ -61,3 +61,17 @@ func TestBookings_GetBookedCount(t *testing.T) {
 		})
 	}
 }
+
+func TestBookings_Active(t *testing.T) {
+	b := Bookings{
+		{StartTime: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)},
+		{StartTime: time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC), Status: BookingCancelled},
+	}
+
+	if got := len(b.Active()); got != 1 {
+		t.Errorf("Active() length = %v, want %v", got, 1)
+	}
+	if got := b.GetBookedCount(time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)); got != 1 {
+		t.Errorf("GetBookedCount() = %v, want %v", got, 1)
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"time"
+)
+
+// meetingCount returns the number of meetings, i.e. distinct start times,
+// of the bookings within [start, end)
+func meetingCount(bookings Bookings, start, end time.Time) int {
+	seen := map[int64]bool{}
+	for _, b := range bookings {
+		if !b.StartTime.Before(start) && b.StartTime.Before(end) {
+			seen[b.StartTime.Unix()] = true
+		}
+	}
+	return len(seen)
+}
+
+// capReached returns true if the meetings in the day and week of t have
+// reached the caps. Days and weeks are computed in the given location
+func capReached(t time.Time, loc *time.Location, dailyCap, weeklyCap int, count func(start, end time.Time) int) bool {
+	lt := t.In(loc)
+	if dailyCap > 0 {
+		dayStart := time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+		if count(dayStart, dayStart.AddDate(0, 0, 1)) >= dailyCap {
+			return true
+		}
+	}
+	if weeklyCap > 0 {
+		weekStart := startOfWeek(lt)
+		if count(weekStart, weekStart.AddDate(0, 0, 7)) >= weeklyCap {
+			return true
+		}
+	}
+	return false
+}
+
+// CapReached returns true if the host can not take another meeting on the
+// day or week of t. The host's schedule location is used, or fallback if the
+// host has no schedule
+func (h Host) CapReached(t time.Time, fallback *time.Location) bool {
+	return h.capReached(t, fallback, nil)
+}
+
+// capReached is CapReached counting the bookings of current instead of the
+// ones of the host's event with the same id, e.g. of a tentative copy
+func (h Host) capReached(t time.Time, fallback *time.Location, current *Event) bool {
+	loc := fallback
+	if h.Schedule != nil && h.Schedule.Location != nil {
+		loc = h.Schedule.Location
+	}
+	return capReached(t, loc, h.DailyCap, h.WeeklyCap, func(start, end time.Time) int {
+		var count int
+		for _, e := range h.Events {
+			if current != nil && e.ID == current.ID {
+				e = current
+			}
+			count += meetingCount(e.BookingsOf(h.ID), start, end)
+		}
+		return count
+	})
+}
+
+// capReached returns true if a new meeting at t exceeds the event's caps or
+// the caps of the hosts who must attend it. Round robin members are checked
+// individually when assigning hosts
+func (e Event) capReached(t time.Time) bool {
+	reached := capReached(t, e.Location, e.DailyCap, e.WeeklyCap, func(start, end time.Time) int {
+		return meetingCount(e.Bookings.Active(), start, end)
+	})
+	if reached {
+		return true
+	}
+
+	if e.Host != nil && e.Host.capReached(t, e.Location, &e) {
+		return true
+	}
+	if e.Kind == Collective {
+		for _, h := range e.Hosts {
+			if h.capReached(t, e.Location, &e) {
+				return true
+			}
+		}
+	}
+	return false
+}
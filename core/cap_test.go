@@ -0,0 +1,174 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func spotTimes(spots []Spot) []time.Time {
+	var times []time.Time
+	for _, s := range spots {
+		times = append(times, s.StartTime)
+	}
+	return times
+}
+
+func TestEvent_GetAvailableSpots_Caps(t *testing.T) {
+	at := func(day, h int) time.Time {
+		return time.Date(2022, 2, day, h, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		event *Event
+		want  []time.Time
+	}{
+		{
+			name: "daily cap hides remaining spots of the day",
+			event: &Event{
+				Duration: 60 * time.Minute,
+				Availability: map[time.Weekday][]Range{
+					time.Monday:  []Range{{StartSec: 0, EndSec: 3 * 3600}},
+					time.Tuesday: []Range{{StartSec: 0, EndSec: 3600}},
+				},
+				Location: time.UTC,
+				Bookings: Bookings{
+					{ID: uuid.New(), StartTime: at(7, 0)},
+					{ID: uuid.New(), StartTime: at(7, 1)},
+				},
+				MaxInvitees: 1,
+				DailyCap:    2,
+			},
+			want: []time.Time{at(8, 0)},
+		},
+		{
+			name: "weekly cap hides remaining spots of the week",
+			event: &Event{
+				Duration: 60 * time.Minute,
+				Availability: map[time.Weekday][]Range{
+					time.Monday:  []Range{{StartSec: 0, EndSec: 3 * 3600}},
+					time.Tuesday: []Range{{StartSec: 0, EndSec: 3600}},
+				},
+				Location: time.UTC,
+				Bookings: Bookings{
+					{ID: uuid.New(), StartTime: at(7, 0)},
+					{ID: uuid.New(), StartTime: at(7, 2)},
+				},
+				MaxInvitees: 1,
+				WeeklyCap:   2,
+			},
+			want: nil,
+		},
+		{
+			name: "cancelled booking does not count",
+			event: &Event{
+				Duration: 60 * time.Minute,
+				Availability: map[time.Weekday][]Range{
+					time.Monday: []Range{{StartSec: 0, EndSec: 2 * 3600}},
+				},
+				Location: time.UTC,
+				Bookings: Bookings{
+					{ID: uuid.New(), StartTime: at(7, 0), Status: BookingCancelled},
+				},
+				MaxInvitees: 1,
+				DailyCap:    1,
+			},
+			want: []time.Time{at(7, 0), at(7, 1)},
+		},
+		{
+			name: "invitees can still join an existing group meeting",
+			event: &Event{
+				Duration: 60 * time.Minute,
+				Availability: map[time.Weekday][]Range{
+					time.Monday: []Range{{StartSec: 0, EndSec: 2 * 3600}},
+				},
+				Location: time.UTC,
+				Bookings: Bookings{
+					{ID: uuid.New(), StartTime: at(7, 0)},
+				},
+				MaxInvitees: 2,
+				DailyCap:    1,
+			},
+			want: []time.Time{at(7, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.event.GetAvailableSpots(GetSpotParameters{
+				Start: at(7, 0),
+				End:   at(9, 0),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, spotTimes(got))
+		})
+	}
+}
+
+func TestHost_Caps(t *testing.T) {
+	jktTime, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	newEvent := func() *Event {
+		return &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Sunday: []Range{{StartSec: 18 * 3600, EndSec: 19 * 3600}},
+				time.Monday: []Range{{StartSec: 0, EndSec: 2 * 3600}},
+			},
+			Location:    time.UTC,
+			MaxInvitees: 1,
+		}
+	}
+	params := GetSpotParameters{
+		Start: time.Date(2022, 2, 6, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	h := NewHost("Foo Bar", "foo@bar.com")
+	h.Schedule = &Schedule{Location: jktTime}
+	h.DailyCap = 1
+
+	interview, other := newEvent(), newEvent()
+	h.AddEvent(interview)
+	h.AddEvent(other)
+
+	// Feb 6 18:00 UTC is already Feb 7 01:00 in Jakarta
+	_, err = other.CreateBooking(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 6, 18, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	got, err := interview.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Empty(t, got, "all slots are on Feb 7 in the host's timezone")
+
+	h.Schedule = nil
+	got, err = interview.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+	}, spotTimes(got), "days are computed in event's timezone without host schedule")
+}
+
+func TestHost_Caps_RoundRobin(t *testing.T) {
+	e, a, _, _ := newRoundRobinEvent(t)
+	a.DailyCap = 1
+	e.Bookings = Bookings{
+		{ID: uuid.New(), HostID: a.ID, StartTime: time.Date(2022, 2, 7, 11, 0, 0, 0, time.UTC)},
+	}
+
+	got, err := e.GetAvailableSpots(GetSpotParameters{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{
+		{StartTime: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC), InviteeRemaining: 2},
+	}, got, "host a has reached the cap and c is busy at 09:00")
+}
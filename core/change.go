@@ -0,0 +1,63 @@
+package core
+
+// ChangeKind is what happened to a booking
+type ChangeKind int
+
+const (
+	ChangeCreated ChangeKind = iota + 1
+	ChangeRescheduled
+	ChangeCancelled
+	ChangeApproved
+	// ChangeDeclined is also used for pending bookings which expired
+	ChangeDeclined
+)
+
+var changeKindNames = map[ChangeKind]string{
+	ChangeCreated:     "created",
+	ChangeRescheduled: "rescheduled",
+	ChangeCancelled:   "cancelled",
+	ChangeApproved:    "approved",
+	ChangeDeclined:    "declined",
+}
+
+func (k ChangeKind) String() string {
+	if name, ok := changeKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// BookingChange describes a change of a booking
+type BookingChange struct {
+	Kind    ChangeKind
+	Booking Booking
+
+	// Previous is the booking before the change. It is empty for created
+	// bookings
+	Previous Booking
+}
+
+// BookingHook is called after a booking is created or changed
+type BookingHook func(e *Event, c BookingChange)
+
+func (e *Event) notifyChange(kind ChangeKind, b Booking, previous Booking) {
+	c := BookingChange{Kind: kind, Booking: b, Previous: previous}
+	if e.OnBookingChange != nil {
+		e.OnBookingChange(e, c)
+	}
+	if d := e.bookingEvent(c); d != nil {
+		e.publish(d)
+	}
+}
+
+// ChainBookingHooks returns a hook calling every hook in order, e.g. to send
+// notifications and schedule reminders. Nil hooks are skipped
+func ChainBookingHooks(hooks ...BookingHook) BookingHook {
+	return func(e *Event, c BookingChange) {
+		for _, h := range hooks {
+			if h != nil {
+				h(e, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestEvent_OnBookingChange(t *testing.T) {
+	var changes []BookingChange
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		OnBookingChange: func(e *Event, c BookingChange) {
+			changes = append(changes, c)
+		},
+	}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.RescheduleBooking(b.ID, slot.Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+
+	var kinds []ChangeKind
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind)
+	}
+	assert.Equal(t, []ChangeKind{ChangeCreated, ChangeRescheduled, ChangeCancelled}, kinds)
+	assert.Equal(t, slot, changes[1].Previous.StartTime)
+	assert.Equal(t, slot.Add(time.Hour), changes[1].Booking.StartTime)
+	assert.Equal(t, BookingConfirmed, changes[2].Previous.Status)
+	assert.Equal(t, BookingCancelled, changes[2].Booking.Status)
+}
+
+func TestChainBookingHooks(t *testing.T) {
+	var calls []string
+	hook := ChainBookingHooks(
+		func(e *Event, c BookingChange) { calls = append(calls, "first") },
+		nil,
+		func(e *Event, c BookingChange) { calls = append(calls, "second") },
+	)
+	hook(&Event{}, BookingChange{Kind: ChangeCreated})
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
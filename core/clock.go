@@ -0,0 +1,58 @@
+package core
+
+import "time"
+
+// Clock tells the time and waits for it, so that time dependent behaviour
+// can be tested with a fake clock, e.g. clocktest.Fake
+type Clock interface {
+	Now() time.Time
+
+	// After sends the time on the returned channel once d elapsed
+	After(d time.Duration) <-chan time.Time
+
+	// NewTicker returns a ticker sending the time every d
+	NewTicker(d time.Duration) Ticker
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the clock of the system
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// Now returns the current time of the event's clock
+func (e Event) Now() time.Time {
+	return e.clock().Now()
+}
+
+// clock returns the event's clock, the system clock if it has none
+func (e Event) clock() Clock {
+	if e.Clock == nil {
+		return SystemClock
+	}
+	return e.Clock
+}
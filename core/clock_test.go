@@ -0,0 +1,62 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/clocktest"
+)
+
+func TestEvent_Clock_ConfirmationWindow(t *testing.T) {
+	now := time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC)
+	clock := clocktest.NewFake(now)
+	e := newApprovalEvent(time.Hour)
+	e.Clock = clock
+
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, now, b.CreatedAt)
+	assert.Equal(t, now.Add(time.Hour), b.ExpiresAt)
+
+	clock.Advance(59 * time.Minute)
+	assert.Equal(t, now.Add(59*time.Minute), e.Now())
+	other, err := e.CreateBooking(CreateBookingParameters{StartTime: time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	_, err = e.ApproveBooking(b.ID)
+	assert.Equal(t, ErrBookingExpired, err)
+
+	approved, err := e.ApproveBooking(other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), approved.Transitions[0].At)
+}
+
+func TestEvent_Clock_PromotionWindow(t *testing.T) {
+	clock := clocktest.NewFake(time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC))
+	e, _ := newFullyBookedEvent(time.Hour)
+	e.Clock = clock
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	first, err := e.JoinWaitlist(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	second, err := e.JoinWaitlist(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(e.Bookings[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(time.Hour), e.Waitlist[0].OfferExpiresAt)
+
+	clock.Advance(time.Hour)
+	_, err = e.ClaimWaitlistOffer(first.ID)
+	assert.True(t, errors.Is(err, ErrWaitlistOfferNotActive))
+
+	// the expired offer moved to the next waitlister
+	clock.Advance(59 * time.Minute)
+	b, err := e.ClaimWaitlistOffer(second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, clock.Now(), b.CreatedAt)
+}
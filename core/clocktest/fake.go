@@ -0,0 +1,149 @@
+// Package clocktest provides a fake core.Clock for tests
+package clocktest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Fake is a clock whose time only moves when it is advanced. Timers and
+// tickers fire during Advance in the order of their time
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	at time.Time
+	c  chan time.Time
+
+	// period is the interval of a ticker, zero for After
+	period time.Duration
+}
+
+// NewFake returns a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &waiter{at: f.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- f.now
+		return w.c
+	}
+	f.add(w)
+	return w.c
+}
+
+// NewTicker returns a ticker firing every d. Like time.Ticker, ticks are
+// dropped if the receiver is not ready
+func (f *Fake) NewTicker(d time.Duration) core.Ticker {
+	if d <= 0 {
+		panic("clocktest: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &waiter{at: f.now.Add(d), c: make(chan time.Time, 1), period: d}
+	f.add(w)
+	return &ticker{clock: f, waiter: w}
+}
+
+// Advance moves the time forward by d and fires the timers and tickers due
+// in between
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	end := f.now.Add(d)
+	for len(f.waiters) > 0 && !f.waiters[0].at.After(end) {
+		w := f.waiters[0]
+		f.now = w.at
+		select {
+		case w.c <- w.at:
+		default:
+		}
+		f.waiters = f.waiters[1:]
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+			f.insert(w)
+		}
+	}
+	if end.After(f.now) {
+		f.now = end
+	}
+	f.changed.Broadcast()
+}
+
+// Set moves the time forward to t. Time never moves backward
+func (f *Fake) Set(t time.Time) {
+	f.Advance(t.Sub(f.Now()))
+}
+
+// BlockUntil waits until n timers or tickers are waiting, e.g. until a
+// goroutine started its ticker, so that Advance fires it
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.changed.Wait()
+	}
+}
+
+func (f *Fake) add(w *waiter) {
+	f.insert(w)
+	f.changed.Broadcast()
+}
+
+// insert keeps the waiters sorted by time, in the order they were added
+// for the same time
+func (f *Fake) insert(w *waiter) {
+	i := sort.Search(len(f.waiters), func(i int) bool {
+		return f.waiters[i].at.After(w.at)
+	})
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+}
+
+func (f *Fake) remove(w *waiter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.waiters {
+		if f.waiters[i] == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			break
+		}
+	}
+	f.changed.Broadcast()
+}
+
+type ticker struct {
+	clock  *Fake
+	waiter *waiter
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.waiter.c
+}
+
+func (t *ticker) Stop() {
+	t.clock.remove(t.waiter)
+}
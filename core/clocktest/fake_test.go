@@ -0,0 +1,62 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	after := f.After(90 * time.Second)
+	ticker := f.NewTicker(time.Minute)
+
+	f.Advance(59 * time.Second)
+	assert.Equal(t, start.Add(59*time.Second), f.Now())
+	assertNotFired(t, after)
+	assertNotFired(t, ticker.C())
+
+	f.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+	assertNotFired(t, after)
+
+	// the ticker drops ticks which are not received
+	f.Advance(3 * time.Minute)
+	assert.Equal(t, start.Add(90*time.Second), <-after)
+	assert.Equal(t, start.Add(2*time.Minute), <-ticker.C())
+	assertNotFired(t, ticker.C())
+
+	ticker.Stop()
+	f.Advance(time.Hour)
+	assertNotFired(t, ticker.C())
+
+	f.Set(start)
+	assert.Equal(t, start.Add(time.Hour+4*time.Minute), f.Now(), "time should not move backward")
+	assert.Equal(t, f.Now(), <-f.After(0))
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC))
+	ticks := make(chan time.Time)
+	go func() {
+		ticker := f.NewTicker(time.Minute)
+		defer ticker.Stop()
+		ticks <- <-ticker.C()
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	assert.Equal(t, f.Now(), <-ticks)
+}
+
+func assertNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case tm := <-c:
+		t.Errorf("unexpected tick at %s", tm)
+	default:
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+)
+
+// Conference is the online meeting of a booking
+type Conference struct {
+	// ID identifies the meeting at the provider
+	ID      string
+	JoinURL string
+	DialIns []DialIn
+}
+
+// DialIn is a phone number to join the meeting
+type DialIn struct {
+	Country string
+	Number  string
+	PIN     string
+}
+
+// IsZero returns true if the booking has no conference
+func (c Conference) IsZero() bool {
+	return c.ID == "" && c.JoinURL == ""
+}
+
+// ConferenceProvider creates online meetings for bookings, e.g. a video
+// conferencing service. Update and Delete receive the booking with the
+// conference returned by Create
+type ConferenceProvider interface {
+	Create(e Event, b Booking) (Conference, error)
+	Update(e Event, b Booking) (Conference, error)
+	Delete(e Event, b Booking) error
+}
+
+// needsConference returns true if the booking takes place at a video link
+// which is generated per booking. This is the case when the event has no
+// meeting location options or the chosen video link option has no fixed URL
+func (e Event) needsConference(b Booking) bool {
+	if e.Conferencing == nil {
+		return false
+	}
+	return b.MeetingLocation.IsZero() ||
+		b.MeetingLocation.Kind == VideoLink && b.MeetingLocation.Value == ""
+}
+
+// createConference creates the conference of a new booking and uses its
+// join URL as the meeting location
+func (e Event) createConference(b *Booking) error {
+	if !e.needsConference(*b) {
+		return nil
+	}
+	c, err := e.Conferencing.Create(e, *b)
+	if err != nil {
+		return fmt.Errorf("create conference: %w", err)
+	}
+	b.Conference = c
+	b.MeetingLocation = MeetingLocation{Kind: VideoLink, Value: c.JoinURL}
+	return nil
+}
+
+// updateConference updates the conference of a rescheduled booking
+func (e Event) updateConference(b *Booking) error {
+	if e.Conferencing == nil || b.Conference.IsZero() {
+		return nil
+	}
+	c, err := e.Conferencing.Update(e, *b)
+	if err != nil {
+		return fmt.Errorf("update conference: %w", err)
+	}
+	b.Conference = c
+	b.MeetingLocation = MeetingLocation{Kind: VideoLink, Value: c.JoinURL}
+	return nil
+}
+
+// discardConference deletes the conference of a booking which is not
+// taking place anymore. Failures are reported to OnConferenceError
+func (e *Event) discardConference(b Booking) {
+	if err := e.deleteConference(b); err != nil && e.OnConferenceError != nil {
+		e.OnConferenceError(e, b, err)
+	}
+}
+
+// restoreConference moves the conference back to the booking whose change
+// was not made. Failures are reported to OnConferenceError
+func (e *Event) restoreConference(b Booking) {
+	if err := e.updateConference(&b); err != nil && e.OnConferenceError != nil {
+		e.OnConferenceError(e, b, err)
+	}
+}
+
+// deleteConference deletes the conference of a booking which is not
+// taking place anymore
+func (e Event) deleteConference(b Booking) error {
+	if e.Conferencing == nil || b.Conference.IsZero() {
+		return nil
+	}
+	if err := e.Conferencing.Delete(e, b); err != nil {
+		return fmt.Errorf("delete conference: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package core_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+type stubConferenceProvider struct {
+	created, updated, deleted int
+	err                       error
+}
+
+func (p *stubConferenceProvider) Create(e Event, b Booking) (Conference, error) {
+	p.created++
+	return Conference{ID: b.ID.String(), JoinURL: "https://meet.example.com/" + b.ID.String()}, p.err
+}
+
+func (p *stubConferenceProvider) Update(e Event, b Booking) (Conference, error) {
+	p.updated++
+	return b.Conference, p.err
+}
+
+func (p *stubConferenceProvider) Delete(e Event, b Booking) error {
+	p.deleted++
+	return p.err
+}
+
+func TestEvent_Conferencing(t *testing.T) {
+	newEvent := func(p ConferenceProvider, options ...MeetingLocation) *Event {
+		return &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+			},
+			Location:         time.UTC,
+			MaxInvitees:      1,
+			MeetingLocations: options,
+			Conferencing:     p,
+		}
+	}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should create conference for generated video link", func(t *testing.T) {
+		p := &stubConferenceProvider{}
+		e := newEvent(p, MeetingLocation{Kind: VideoLink}, MeetingLocation{Kind: InPerson, Value: "office"})
+		b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot, MeetingLocation: MeetingLocation{Kind: VideoLink}})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://meet.example.com/"+b.ID.String(), b.MeetingLocation.Value)
+		assert.Equal(t, b.Conference, e.Bookings[0].Conference)
+		assert.Equal(t, 1, p.created)
+	})
+
+	t.Run("should not create conference for other locations", func(t *testing.T) {
+		p := &stubConferenceProvider{}
+		e := newEvent(p, MeetingLocation{Kind: VideoLink}, MeetingLocation{Kind: InPerson, Value: "office"})
+		b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot, MeetingLocation: MeetingLocation{Kind: InPerson, Value: "office"}})
+		assert.NoError(t, err)
+		assert.True(t, b.Conference.IsZero())
+
+		_, err = e.RescheduleBooking(b.ID, slot.Add(time.Hour))
+		assert.NoError(t, err)
+		_, err = e.CancelBooking(b.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, stubConferenceProvider{}, *p)
+	})
+
+	t.Run("should keep booking unchanged if provider fails", func(t *testing.T) {
+		p := &stubConferenceProvider{}
+		e := newEvent(p)
+		b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+		assert.NoError(t, err)
+
+		p.err = fmt.Errorf("service unavailable")
+		_, err = e.CreateBooking(CreateBookingParameters{StartTime: slot.Add(time.Hour)})
+		assert.Error(t, err)
+		assert.Len(t, e.Bookings, 1)
+
+		_, err = e.RescheduleBooking(b.ID, slot.Add(time.Hour))
+		assert.Error(t, err)
+		assert.Equal(t, slot, e.Bookings[0].StartTime)
+
+	})
+
+	t.Run("should cancel booking and report if provider fails", func(t *testing.T) {
+		p := &stubConferenceProvider{}
+		e := newEvent(p)
+		var reported error
+		e.OnConferenceError = func(e *Event, b Booking, err error) { reported = err }
+		b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+		assert.NoError(t, err)
+
+		p.err = fmt.Errorf("service unavailable")
+		_, err = e.CancelBooking(b.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, BookingCancelled, e.Bookings[0].Status)
+		assert.True(t, errors.Is(reported, p.err))
+	})
+
+	t.Run("should delete conference of declined and expired bookings", func(t *testing.T) {
+		p := &stubConferenceProvider{}
+		e := newEvent(p)
+		e.RequiresConfirmation = true
+		e.ConfirmationWindow = time.Hour
+		var reported []error
+		e.OnConferenceError = func(e *Event, b Booking, err error) { reported = append(reported, err) }
+		declined, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+		assert.NoError(t, err)
+		expired, err := e.CreateBooking(CreateBookingParameters{StartTime: slot.Add(time.Hour)})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, p.created)
+
+		_, err = e.DeclineBooking(declined.ID, "busy")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, p.deleted)
+
+		p.err = fmt.Errorf("service unavailable")
+		got, err := e.ExpirePendingBookings(expired.ExpiresAt)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, 2, p.deleted)
+		assert.Len(t, reported, 1)
+		assert.True(t, errors.Is(reported[0], p.err))
+	})
+}
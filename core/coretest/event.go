@@ -0,0 +1,28 @@
+// Package coretest provides core fixtures for tests
+package coretest
+
+import (
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// NewEvent returns an event of Foo Bar <foo@bar.com> taking one invitee per
+// spot, available all day every day in UTC
+func NewEvent(duration time.Duration) *core.Event {
+	allDay := []core.Range{{StartSec: 0, EndSec: 24 * 3600}}
+	availability := map[time.Weekday][]core.Range{}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		availability[d] = allDay
+	}
+	e := &core.Event{
+		Name:         "Chat",
+		Duration:     duration,
+		Availability: availability,
+		Location:     time.UTC,
+		MaxInvitees:  1,
+	}
+	h := core.NewHost("Foo Bar", "foo@bar.com")
+	h.AddEvent(e)
+	return e
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldChange is the value of a field before and after a change, formatted
+// for humans. Before is empty for new values
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+type field struct {
+	name, value string
+}
+
+// diff returns the fields whose value changed
+func diff(before, after []field) []FieldChange {
+	var changes []FieldChange
+	for i := range after {
+		if before[i].value != after[i].value {
+			changes = append(changes, FieldChange{
+				Field:  after[i].name,
+				Before: before[i].value,
+				After:  after[i].value,
+			})
+		}
+	}
+	return changes
+}
+
+// settings returns the audited settings of the event
+func (e Event) settings() []field {
+	location := ""
+	if e.Location != nil {
+		location = e.Location.String()
+	}
+	return []field{
+		{"name", e.Name},
+		{"location", location},
+		{"duration", e.Duration.String()},
+		{"max_invitees", strconv.Itoa(e.MaxInvitees)},
+		{"availability", formatAvailability(e.Availability)},
+		{"date_overrides", formatDateOverrides(e.DateOverrides, e.Location)},
+		{"holidays", formatHolidays(e.Holidays)},
+		{"exceptions", formatExceptions(e.Exceptions, e.Location)},
+		{"travels", formatTravels(e.Travels)},
+		{"questions", formatQuestions(e.Questions)},
+		{"meeting_locations", formatMeetingLocations(e.MeetingLocations)},
+		{"hosts", formatHosts(e.Hosts)},
+		{"requires_confirmation", strconv.FormatBool(e.RequiresConfirmation)},
+		{"confirmation_window", e.ConfirmationWindow.String()},
+		{"daily_cap", strconv.Itoa(e.DailyCap)},
+		{"weekly_cap", strconv.Itoa(e.WeeklyCap)},
+		{"active_from", formatDate(e.ActiveFrom, e.Location)},
+		{"active_until", formatDate(e.ActiveUntil, e.Location)},
+		{"paused", strconv.FormatBool(e.Paused)},
+	}
+}
+
+// DiffBookings returns the changed fields of the booking. A zero before
+// returns the initial values of the booking
+func DiffBookings(before, after Booking) []FieldChange {
+	fields := func(b Booking) []field {
+		if b.ID == uuid.Nil {
+			return make([]field, 5)
+		}
+		host := ""
+		if b.HostID != uuid.Nil {
+			host = b.HostID.String()
+		}
+		return []field{
+			{"status", b.Status.String()},
+			{"start_time", b.StartTime.UTC().Format(time.RFC3339)},
+			{"host_id", host},
+			{"meeting_location", b.MeetingLocation.String()},
+			{"join_url", b.Conference.JoinURL},
+		}
+	}
+	return diff(fields(before), fields(after))
+}
+
+// formatAvailability formats the weekly availability, e.g.
+// Monday 09:00-12:00 13:00-17:00; Tuesday 09:00-12:00
+func formatAvailability(availability map[time.Weekday][]Range) string {
+	var days []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if ranges, ok := availability[d]; ok {
+			days = append(days, strings.TrimSpace(d.String()+" "+formatRanges(ranges)))
+		}
+	}
+	return strings.Join(days, "; ")
+}
+
+// formatDateOverrides formats the overrides by date, e.g.
+// 2022-02-07 09:00-10:00; 2022-02-08 unavailable
+func formatDateOverrides(overrides map[int64][]Range, loc *time.Location) string {
+	keys := make([]int64, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var dates []string
+	for _, k := range keys {
+		ranges := formatRanges(overrides[k])
+		if ranges == "" {
+			ranges = "unavailable"
+		}
+		dates = append(dates, formatDate(time.Unix(k, 0), loc)+" "+ranges)
+	}
+	return strings.Join(dates, "; ")
+}
+
+// formatHolidays formats the names of the holiday calendars, e.g. ID, DE
+func formatHolidays(calendars []*HolidayCalendar) string {
+	names := make([]string, 0, len(calendars))
+	for _, c := range calendars {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatExceptions formats the exceptions, e.g.
+// No meetings: remove FREQ=MONTHLY;BYDAY=1MO from 2022-02-07
+func formatExceptions(exceptions []AvailabilityException, loc *time.Location) string {
+	formatted := make([]string, 0, len(exceptions))
+	for _, x := range exceptions {
+		action := "remove"
+		if x.Action == ReplaceRanges {
+			action = "replace"
+		}
+		s := x.Name + ": " + action + " " + x.Recurrence.String() + " from " + formatDate(x.Start, loc)
+		if ranges := formatRanges(x.Ranges); ranges != "" {
+			s += " " + ranges
+		}
+		formatted = append(formatted, s)
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// formatTravels formats the travels, e.g. 2022-02-07..2022-02-10 Europe/Berlin
+func formatTravels(travels []Travel) string {
+	formatted := make([]string, 0, len(travels))
+	for _, t := range travels {
+		location := ""
+		if t.Location != nil {
+			location = t.Location.String()
+		}
+		formatted = append(formatted, formatDate(t.Start, nil)+".."+formatDate(t.End, nil)+" "+location)
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// formatQuestions formats the questions, e.g.
+// company (text) Company; size (single_choice, required) Size [1-10, 11+]
+func formatQuestions(questions Questions) string {
+	formatted := make([]string, 0, len(questions))
+	for _, q := range questions {
+		kind := q.Type.String()
+		if q.Required {
+			kind += ", required"
+		}
+		s := q.ID + " (" + kind + ") " + q.Label
+		if len(q.Options) > 0 {
+			s += " [" + strings.Join(q.Options, ", ") + "]"
+		}
+		formatted = append(formatted, s)
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// formatMeetingLocations formats the options, e.g. in_person Office; video_link
+func formatMeetingLocations(locations []MeetingLocation) string {
+	formatted := make([]string, 0, len(locations))
+	for _, l := range locations {
+		formatted = append(formatted, strings.TrimSpace(l.Kind.String()+" "+l.Value))
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// formatHosts formats the emails of the team members
+func formatHosts(hosts []*Host) string {
+	emails := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		emails = append(emails, h.Email)
+	}
+	return strings.Join(emails, ", ")
+}
+
+func formatRanges(ranges []Range) string {
+	formatted := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		end := r.End()
+		if r.EndSec == 24*3600 {
+			end = "24:00"
+		}
+		formatted = append(formatted, r.Start()+"-"+end)
+	}
+	return strings.Join(formatted, " ")
+}
+
+func formatDate(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t.Format("2006-01-02")
+}
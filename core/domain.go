@@ -0,0 +1,163 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEvent is something which happened to an event or its bookings. It is
+// published to the event's Publisher after the change is made
+type DomainEvent interface {
+	// Topic names the kind of domain event, e.g. booking.created
+	Topic() string
+	OccurredAt() time.Time
+}
+
+// Publisher receives the domain events of an event, e.g. eventbus.Bus
+type Publisher interface {
+	Publish(d DomainEvent)
+}
+
+type BookingCreatedEvent struct {
+	EventID uuid.UUID
+	Booking Booking
+	At      time.Time
+	Actor   string
+}
+
+type BookingRescheduledEvent struct {
+	EventID  uuid.UUID
+	Booking  Booking
+	Previous Booking
+	At       time.Time
+	Actor    string
+}
+
+type BookingCancelledEvent struct {
+	EventID  uuid.UUID
+	Booking  Booking
+	Previous Booking
+	At       time.Time
+	Actor    string
+}
+
+type BookingApprovedEvent struct {
+	EventID  uuid.UUID
+	Booking  Booking
+	Previous Booking
+	At       time.Time
+	Actor    string
+}
+
+// BookingDeclinedEvent is also published for pending bookings which expired
+type BookingDeclinedEvent struct {
+	EventID  uuid.UUID
+	Booking  Booking
+	Previous Booking
+	At       time.Time
+	Actor    string
+}
+
+// WaitlistPromotedEvent is published when a waitlister is offered a seat or
+// booked
+type WaitlistPromotedEvent struct {
+	EventID uuid.UUID
+	Entry   WaitlistEntry
+	At      time.Time
+	Actor   string
+}
+
+// EventUpdatedEvent is published when the event's settings are changed by
+// Update
+type EventUpdatedEvent struct {
+	EventID uuid.UUID
+	Changes []FieldChange
+	At      time.Time
+	Actor   string
+}
+
+func (BookingCreatedEvent) Topic() string     { return "booking.created" }
+func (BookingRescheduledEvent) Topic() string { return "booking.rescheduled" }
+func (BookingCancelledEvent) Topic() string   { return "booking.cancelled" }
+func (BookingApprovedEvent) Topic() string    { return "booking.approved" }
+func (BookingDeclinedEvent) Topic() string    { return "booking.declined" }
+func (WaitlistPromotedEvent) Topic() string   { return "waitlist.promoted" }
+func (EventUpdatedEvent) Topic() string       { return "event.updated" }
+
+func (d BookingCreatedEvent) OccurredAt() time.Time     { return d.At }
+func (d BookingRescheduledEvent) OccurredAt() time.Time { return d.At }
+func (d BookingCancelledEvent) OccurredAt() time.Time   { return d.At }
+func (d BookingApprovedEvent) OccurredAt() time.Time    { return d.At }
+func (d BookingDeclinedEvent) OccurredAt() time.Time    { return d.At }
+func (d WaitlistPromotedEvent) OccurredAt() time.Time   { return d.At }
+func (d EventUpdatedEvent) OccurredAt() time.Time       { return d.At }
+
+// Update applies the change to the event's settings and publishes
+// EventUpdatedEvent with the changed settings, e.g.
+// e.Update(func(e *Event) { e.Paused = true }). Nothing is published if no
+// setting changed
+func (e *Event) Update(change func(e *Event)) {
+	before := e.settings()
+	change(e)
+	changes := diff(before, e.settings())
+	if len(changes) == 0 {
+		return
+	}
+	e.publish(EventUpdatedEvent{EventID: e.ID, Changes: changes, At: e.Now(), Actor: e.actor})
+}
+
+// As makes the changes on behalf of the actor, e.g. the email of the host
+// cancelling a booking. The actor is set on the domain events published by
+// do. Changes made outside of As have no actor
+func (e *Event) As(actor string, do func(e *Event) error) error {
+	previous := e.actor
+	e.actor = actor
+	defer func() { e.actor = previous }()
+	return do(e)
+}
+
+func (e *Event) publish(d DomainEvent) {
+	if e.Publisher != nil {
+		e.Publisher.Publish(d)
+	}
+}
+
+// bookingEvent returns the domain event of the booking change
+func (e *Event) bookingEvent(c BookingChange) DomainEvent {
+	now := e.Now()
+	switch c.Kind {
+	case ChangeCreated:
+		return BookingCreatedEvent{EventID: e.ID, Booking: c.Booking, At: now, Actor: e.actor}
+	case ChangeRescheduled:
+		return BookingRescheduledEvent{EventID: e.ID, Booking: c.Booking, Previous: c.Previous, At: now, Actor: e.actor}
+	case ChangeCancelled:
+		return BookingCancelledEvent{EventID: e.ID, Booking: c.Booking, Previous: c.Previous, At: now, Actor: e.actor}
+	case ChangeApproved:
+		return BookingApprovedEvent{EventID: e.ID, Booking: c.Booking, Previous: c.Previous, At: now, Actor: e.actor}
+	case ChangeDeclined:
+		return BookingDeclinedEvent{EventID: e.ID, Booking: c.Booking, Previous: c.Previous, At: now, Actor: e.actor}
+	default:
+		return nil
+	}
+}
+
+// BookingChangeOf returns the booking change of the domain event and the id
+// of its event. It is the reverse of the events published for changes, e.g.
+// to call booking hooks from a bus
+func BookingChangeOf(d DomainEvent) (eventID uuid.UUID, c BookingChange, ok bool) {
+	switch d := d.(type) {
+	case BookingCreatedEvent:
+		return d.EventID, BookingChange{Kind: ChangeCreated, Booking: d.Booking}, true
+	case BookingRescheduledEvent:
+		return d.EventID, BookingChange{Kind: ChangeRescheduled, Booking: d.Booking, Previous: d.Previous}, true
+	case BookingCancelledEvent:
+		return d.EventID, BookingChange{Kind: ChangeCancelled, Booking: d.Booking, Previous: d.Previous}, true
+	case BookingApprovedEvent:
+		return d.EventID, BookingChange{Kind: ChangeApproved, Booking: d.Booking, Previous: d.Previous}, true
+	case BookingDeclinedEvent:
+		return d.EventID, BookingChange{Kind: ChangeDeclined, Booking: d.Booking, Previous: d.Previous}, true
+	default:
+		return uuid.Nil, BookingChange{}, false
+	}
+}
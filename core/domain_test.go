@@ -0,0 +1,189 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+type recordingPublisher struct {
+	published []DomainEvent
+}
+
+func (p *recordingPublisher) Publish(d DomainEvent) {
+	p.published = append(p.published, d)
+}
+
+func (p *recordingPublisher) Topics() []string {
+	var topics []string
+	for _, d := range p.published {
+		topics = append(topics, d.Topic())
+	}
+	return topics
+}
+
+func TestEvent_Publisher(t *testing.T) {
+	publisher := &recordingPublisher{}
+	e := &Event{
+		ID:       uuid.New(),
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Publisher:   publisher,
+	}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.JoinWaitlist(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.RescheduleBooking(b.ID, slot.Add(time.Hour))
+	assert.NoError(t, err)
+	e.Update(func(e *Event) { e.Paused = true })
+
+	assert.Equal(t, []string{
+		"booking.created",
+		"booking.rescheduled",
+		"booking.created",
+		"waitlist.promoted",
+		"event.updated",
+	}, publisher.Topics())
+
+	created := publisher.published[0].(BookingCreatedEvent)
+	assert.Equal(t, e.ID, created.EventID)
+	assert.Equal(t, b.ID, created.Booking.ID)
+
+	rescheduled := publisher.published[1].(BookingRescheduledEvent)
+	assert.Equal(t, slot, rescheduled.Previous.StartTime)
+	assert.Equal(t, slot.Add(time.Hour), rescheduled.Booking.StartTime)
+
+	// the freed seat is given to the waitlister
+	promoted := publisher.published[3].(WaitlistPromotedEvent)
+	assert.Equal(t, WaitlistPromoted, promoted.Entry.Status)
+	assert.Equal(t, publisher.published[2].(BookingCreatedEvent).Booking.ID, promoted.Entry.BookingID)
+	assert.True(t, e.Paused)
+}
+
+func TestEvent_Update(t *testing.T) {
+	publisher := &recordingPublisher{}
+	e := &Event{
+		ID:       uuid.New(),
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 12 * 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Publisher:   publisher,
+	}
+
+	err := e.As("host@example.com", func(e *Event) error {
+		e.Update(func(e *Event) {
+			e.Availability[time.Tuesday] = []Range{{StartSec: 13 * 3600, EndSec: 24 * 3600}}
+			e.DateOverrides = map[int64][]Range{
+				time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC).Unix(): nil,
+			}
+			e.Duration = time.Hour
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// nothing changed
+	e.Update(func(e *Event) { e.Duration = time.Hour })
+
+	assert.Len(t, publisher.published, 1)
+	updated := publisher.published[0].(EventUpdatedEvent)
+	assert.Equal(t, "host@example.com", updated.Actor)
+	assert.Equal(t, []FieldChange{
+		{Field: "duration", Before: "30m0s", After: "1h0m0s"},
+		{Field: "availability", Before: "Monday 09:00-12:00", After: "Monday 09:00-12:00; Tuesday 13:00-24:00"},
+		{Field: "date_overrides", Before: "", After: "2022-02-07 unavailable"},
+	}, updated.Changes)
+}
+
+func TestEvent_Update_Availability(t *testing.T) {
+	publisher := &recordingPublisher{}
+	e := &Event{
+		ID:       uuid.New(),
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 12 * 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Publisher:   publisher,
+	}
+	holidays, err := NewHolidayCalendar("DE")
+	assert.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	err = e.As("host@example.com", func(e *Event) error {
+		e.Update(func(e *Event) {
+			e.Exceptions = append(e.Exceptions, AvailabilityException{
+				Name:       "Planning",
+				Start:      time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{Freq: Monthly, ByDay: []WeekdayNum{{N: 1, Weekday: time.Monday}}},
+				Ranges:     []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+			})
+			e.Holidays = append(e.Holidays, holidays)
+			e.Travels = append(e.Travels, Travel{
+				Start:    time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC),
+				End:      time.Date(2022, 2, 18, 0, 0, 0, 0, time.UTC),
+				Location: berlin,
+			})
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, publisher.published, 1)
+	updated := publisher.published[0].(EventUpdatedEvent)
+	assert.Equal(t, "host@example.com", updated.Actor)
+	assert.Equal(t, []FieldChange{
+		{Field: "holidays", Before: "", After: "DE"},
+		{Field: "exceptions", Before: "", After: "Planning: remove FREQ=MONTHLY;BYDAY=1MO from 2022-02-07 09:00-10:00"},
+		{Field: "travels", Before: "", After: "2022-02-14..2022-02-18 Europe/Berlin"},
+	}, updated.Changes)
+}
+
+func TestEvent_As(t *testing.T) {
+	publisher := &recordingPublisher{}
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Publisher:   publisher,
+	}
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	err = e.As("host@example.com", func(e *Event) error {
+		_, err := e.CancelBooking(b.ID)
+		return err
+	})
+	assert.NoError(t, err)
+	err = e.As("host@example.com", func(e *Event) error {
+		_, err := e.CancelBooking(b.ID)
+		return err
+	})
+	assert.Equal(t, ErrBookingCancelled, err)
+
+	assert.Len(t, publisher.published, 2)
+	assert.Equal(t, "", publisher.published[0].(BookingCreatedEvent).Actor)
+	cancelled := publisher.published[1].(BookingCancelledEvent)
+	assert.Equal(t, "host@example.com", cancelled.Actor)
+	assert.Equal(t, BookingConfirmed, cancelled.Previous.Status)
+	assert.Equal(t, BookingCancelled, cancelled.Booking.Status)
+}
@@ -13,9 +13,24 @@ type Event struct {
 	ID   uuid.UUID
 	Name string
 
+	// Host is the owner of this event. Bookings on other events of the
+	// same host make the overlapping spots unavailable
+	Host *Host
+
 	// Location defines the timezone used by calendar creator
 	Location *time.Location
 
+	// MeetingLocations are the places the invitee can choose from
+	MeetingLocations []MeetingLocation
+
+	// Conferencing generates the video link of bookings without a fixed
+	// meeting location
+	Conferencing ConferenceProvider
+
+	// OnConferenceError is notified when the conference of a cancelled
+	// booking can not be deleted. The booking is cancelled anyway
+	OnConferenceError func(e *Event, b Booking, err error)
+
 	// Duration defines how long an event should take
 	Duration time.Duration
 
@@ -27,11 +42,97 @@ type Event struct {
 	// key is timestamp millis of the 00:00:00 for the given day
 	DateOverrides map[int64][]Range
 
+	// Holidays are the holiday calendars whose holidays are unavailable
+	Holidays []*HolidayCalendar
+
+	// Exceptions change the weekly availability of the days matching
+	// their rules
+	Exceptions []AvailabilityException
+
+	// Travels change the timezone of the availability on their dates,
+	// e.g. working hours follow the host on a business trip
+	Travels []Travel
+
 	// Bookings stores all booking created for this event
 	Bookings Bookings
 
+	// BusyTimes stores time taken outside of this application, e.g.
+	// meetings imported from the host's external calendars
+	BusyTimes Intervals
+
+	// BusySources are asked for the time taken outside of this application
+	// within the window of each query
+	BusySources []BusySource
+
 	// MaxInvitees shows maximum number of booking can be created
 	MaxInvitees int
+
+	// Questions are asked to the invitee when booking
+	Questions Questions
+
+	// RequiresConfirmation makes new bookings pending until the host
+	// approves them
+	RequiresConfirmation bool
+
+	// ConfirmationWindow is how long a pending booking holds its seat
+	// before it is declined. Zero holds it until the host decides
+	ConfirmationWindow time.Duration
+
+	// Kind defines how the event is hosted
+	Kind EventKind
+
+	// Hosts are the team members of a round robin or collective event
+	Hosts []*Host
+
+	// Assignment defines how a round robin booking picks its host
+	Assignment AssignmentStrategy
+
+	// HostWeights is used by WeightedPriority assignment, key is host id.
+	// Hosts without weight have weight 1
+	HostWeights map[uuid.UUID]int
+
+	// Waitlist stores invitees waiting for a seat of fully booked spots
+	Waitlist Waitlist
+
+	// PromotionWindow is how long a freed seat is held for the next
+	// waitlister before it is offered to the one after. Zero books the
+	// next waitlister right away
+	PromotionWindow time.Duration
+
+	// OnWaitlistPromotion is notified when a waitlister is offered a seat
+	// or booked
+	OnWaitlistPromotion WaitlistHook
+
+	// OnBookingChange is notified when a booking is created or changed,
+	// e.g. to send notifications
+	OnBookingChange BookingHook
+
+	// Publisher receives the domain events of the event and its bookings
+	Publisher Publisher
+
+	// Journal stores every booking change before it is made. Nil keeps the
+	// bookings in memory only
+	Journal Journal
+
+	// actor is who makes the current changes, see As
+	actor string
+
+	// DailyCap and WeeklyCap limit the number of meetings of this event per
+	// day and per week (starting on monday) in the event's location. Zero
+	// means unlimited
+	DailyCap, WeeklyCap int
+
+	// ActiveFrom and ActiveUntil limit the dates on which spots are offered,
+	// e.g. for a seasonal event. Both dates are inclusive and compared in the
+	// event's location. Zero means unbounded
+	ActiveFrom, ActiveUntil time.Time
+
+	// Paused events keep their bookings but do not accept new ones
+	Paused bool
+
+	// Clock tells the time of bookings, holds and expiries. SystemClock is
+	// used if it is nil
+	Clock Clock
 }
 
 type GetSpotParameters struct {
@@ -55,6 +156,10 @@ func (e Event) GetAvailableSpots(params GetSpotParameters) ([]Spot, error) {
 	if err := params.IsValid(); err != nil {
 		return nil, err
 	}
+	if e.Paused {
+		return nil, nil
+	}
+	e.Bookings = e.Bookings.holding(e.Now())
 
 	start := params.Start.In(e.Location)
 	end := params.End.In(e.Location)
@@ -62,46 +167,158 @@ func (e Event) GetAvailableSpots(params GetSpotParameters) ([]Spot, error) {
 	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
 	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
 
+	windowEnd := endDay.AddDate(0, 0, 1)
+	busy := e.busyIntervals(startDay, windowEnd)
+	var teamBusy map[uuid.UUID]Intervals
+	if e.Kind != SingleHost {
+		teamBusy = e.teamBusyIntervals(startDay, windowEnd)
+	}
+
 	var spots []Spot
 
 	curr := startDay
-	for {
-		var ranges []Range
-		if dateOverrides, ok := e.DateOverrides[curr.Unix()]; ok {
-			ranges = dateOverrides
-		} else if scheduleRanges, ok := e.Availability[curr.Weekday()]; ok {
-			ranges = scheduleRanges
+	for ; !curr.After(endDay); curr = curr.Add(24 * time.Hour) {
+		if !e.IsActiveOn(curr) {
+			continue
 		}
 
-		for _, r := range ranges {
-			for _, slot := range r.Slots(curr, e.Duration) {
-				remainingSpot := e.MaxInvitees - e.Bookings.GetBookedCount(slot)
-				if remainingSpot > 0 &&
-					(slot.Equal(start) || slot.After(start) && slot.Before(end)) {
-					spots = append(spots, Spot{
-						InviteeRemaining: remainingSpot,
-						StartTime:        slot,
-					})
-				}
-			}
+		var daySpots []Spot
+		switch e.Kind {
+		case RoundRobin:
+			daySpots = e.roundRobinSpots(curr, teamBusy)
+		case Collective:
+			daySpots = e.spotsWithin(curr, e.collectiveRanges(curr, busy, teamBusy))
+		default:
+			free := e.Schedule().RangesOnDate(curr).Subtract(busy.RangesOn(curr))
+			daySpots = e.spotsWithin(curr, free)
 		}
-		curr = curr.Add(24 * time.Hour)
-		if curr.After(endDay) {
-			break
+
+		for _, spot := range daySpots {
+			slot := spot.StartTime
+			if !(slot.Equal(start) || slot.After(start) && slot.Before(end)) {
+				continue
+			}
+			// joining an existing group meeting does not add a new meeting
+			joining := e.Kind != RoundRobin && e.Bookings.GetBookedCount(slot) > 0
+			if !joining && e.capReached(slot) {
+				continue
+			}
+			spots = append(spots, spot)
 		}
 	}
 	return spots, nil
 }
 
+// IsActiveOn returns true if the day is within the event's active window
+func (e Event) IsActiveOn(day time.Time) bool {
+	date := func(t time.Time) string {
+		return t.In(e.Location).Format("2006-01-02")
+	}
+	if !e.ActiveFrom.IsZero() && date(day) < date(e.ActiveFrom) {
+		return false
+	}
+	if !e.ActiveUntil.IsZero() && date(day) > date(e.ActiveUntil) {
+		return false
+	}
+	return true
+}
+
+// busyIntervals returns the time taken outside of this event within
+// [from, to)
+func (e Event) busyIntervals(from, to time.Time) Intervals {
+	busy := append(Intervals(nil), e.BusyTimes...)
+	busy = append(busy, busyFrom(e.BusySources, from, to)...)
+	if e.Host != nil {
+		busy = append(busy, e.Host.BusyIntervals(e.ID, from, to)...)
+	}
+	return busy
+}
+
+// spotsWithin returns the spots of the day within the free ranges which
+// still have room for more invitees
+func (e Event) spotsWithin(day time.Time, free RangeSet) []Spot {
+	now := e.Now()
+	var spots []Spot
+	for _, slot := range free.Slots(day, e.Duration) {
+		remainingSpot := e.MaxInvitees - e.Bookings.GetBookedCount(slot) - e.Waitlist.HeldCount(slot, now)
+		if remainingSpot > 0 {
+			spots = append(spots, Spot{
+				InviteeRemaining: remainingSpot,
+				StartTime:        slot,
+			})
+		}
+	}
+	return spots
+}
+
+// Schedule returns the event's availability
+func (e Event) Schedule() Schedule {
+	return Schedule{
+		Location:      e.Location,
+		Availability:  e.Availability,
+		DateOverrides: e.DateOverrides,
+		Holidays:      e.Holidays,
+		Exceptions:    e.Exceptions,
+		Travels:       e.Travels,
+	}
+}
+
+// AvailableIntervals returns the time within [start, end) covered by the
+// event's availability, regardless of bookings
+func (e Event) AvailableIntervals(start, end time.Time) Intervals {
+	return e.Schedule().AvailableIntervals(start, end)
+}
+
 type CreateBookingParameters struct {
 	Invitee   Invitee
 	StartTime time.Time
+	Answers   Answers
+
+	// MeetingLocation is the invitee's choice among the event's meeting
+	// locations. It may be empty if the event has a single option
+	MeetingLocation MeetingLocation
 }
 
-var ErrTimeNotAvailable = fmt.Errorf("no time available")
+var (
+	ErrTimeNotAvailable          = fmt.Errorf("no time available")
+	ErrBookingNotFound           = fmt.Errorf("booking not found")
+	ErrBookingCancelled          = fmt.Errorf("booking has been cancelled")
+	ErrEventNotAcceptingBookings = fmt.Errorf("event not accepting bookings")
+)
 
 // CreateBooking create new booking for given schedule if it is available
 func (e *Event) CreateBooking(params CreateBookingParameters) (*Booking, error) {
+	b, err := e.prepareBooking(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.createConference(b); err != nil {
+		return nil, err
+	}
+	if err := e.journal(BookingChange{Kind: ChangeCreated, Booking: *b}); err != nil {
+		e.discardConference(*b)
+		return nil, err
+	}
+	e.Bookings = append(e.Bookings, *b)
+	e.notifyChange(ChangeCreated, *b, Booking{})
+	return b, nil
+}
+
+// prepareBooking validates the parameters and returns the new booking
+// without storing it
+func (e Event) prepareBooking(params CreateBookingParameters) (*Booking, error) {
+	if e.Paused {
+		return nil, ErrEventNotAcceptingBookings
+	}
+	answers, err := e.Questions.Validate(params.Answers)
+	if err != nil {
+		return nil, err
+	}
+	params.Answers = answers
+	if params.MeetingLocation, err = e.chooseMeetingLocation(params.MeetingLocation); err != nil {
+		return nil, err
+	}
+
 	availableSpots, err := e.GetAvailableSpots(GetSpotParameters{
 		Start: params.StartTime,
 		End:   params.StartTime.Add(e.Duration),
@@ -112,10 +329,94 @@ func (e *Event) CreateBooking(params CreateBookingParameters) (*Booking, error)
 
 	for _, spot := range availableSpots {
 		if spot.StartTime.Equal(params.StartTime) {
-			b := NewBooking(params)
-			e.Bookings = append(e.Bookings, *b)
+			b := e.newBooking(params)
+			if e.Kind == RoundRobin {
+				b.HostID = e.assignHost(e.freeHosts(params.StartTime), params.StartTime).ID
+			}
 			return b, nil
 		}
 	}
 	return nil, ErrTimeNotAvailable
 }
+
+// CancelBooking cancels the booking and releases its spot
+func (e *Event) CancelBooking(id uuid.UUID) (*Booking, error) {
+	i := e.Bookings.Find(id)
+	if i < 0 {
+		return nil, ErrBookingNotFound
+	}
+	if !e.Bookings[i].IsActive() {
+		return nil, e.Bookings[i].inactiveError()
+	}
+
+	previous := e.Bookings[i]
+	now := e.Now()
+	b := previous
+	b.transition(BookingCancelled, now, "")
+	b.CancelledAt = now
+	b.Sequence++
+	if err := e.journal(BookingChange{Kind: ChangeCancelled, Booking: b, Previous: previous}); err != nil {
+		return nil, err
+	}
+	// a provider outage must not keep invitees from cancelling
+	e.discardConference(previous)
+
+	e.Bookings[i] = b
+	e.notifyChange(ChangeCancelled, b, previous)
+	e.promoteWaitlist(b.StartTime, now)
+	return &b, nil
+}
+
+// RescheduleBooking moves the booking to a new start time if it is available.
+// The spot taken by the booking itself is considered free
+func (e *Event) RescheduleBooking(id uuid.UUID, startTime time.Time) (*Booking, error) {
+	i := e.Bookings.Find(id)
+	if i < 0 {
+		return nil, ErrBookingNotFound
+	}
+	if !e.Bookings[i].IsActive() {
+		return nil, e.Bookings[i].inactiveError()
+	}
+	if e.Paused {
+		return nil, ErrEventNotAcceptingBookings
+	}
+
+	others := *e
+	others.Bookings = append(append(Bookings(nil), e.Bookings[:i]...), e.Bookings[i+1:]...)
+	availableSpots, err := others.GetAvailableSpots(GetSpotParameters{
+		Start: startTime,
+		End:   startTime.Add(e.Duration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spot := range availableSpots {
+		if spot.StartTime.Equal(startTime) {
+			b := e.Bookings[i]
+			if e.Kind == RoundRobin {
+				hosts := others.freeHosts(startTime)
+				if !containsHost(hosts, b.HostID) {
+					b.HostID = others.assignHost(hosts, startTime).ID
+				}
+			}
+			previous := b.StartTime
+			b.StartTime = startTime
+			b.Sequence++
+			if err := e.updateConference(&b); err != nil {
+				return nil, err
+			}
+
+			old := e.Bookings[i]
+			if err := e.journal(BookingChange{Kind: ChangeRescheduled, Booking: b, Previous: old}); err != nil {
+				e.restoreConference(old)
+				return nil, err
+			}
+			e.Bookings[i] = b
+			e.notifyChange(ChangeRescheduled, b, old)
+			e.promoteWaitlist(previous, e.Now())
+			return &b, nil
+		}
+	}
+	return nil, ErrTimeNotAvailable
+}
@@ -416,6 +416,77 @@ func TestEvent_GetAvailableSlots(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should remove external busy time from available slots",
+			fields: fields{
+				Event: &Event{
+					Duration: 30 * time.Minute,
+					Availability: map[time.Weekday][]Range{
+						time.Monday: []Range{
+							{
+								StartSec: 0,
+								EndSec:   7200,
+							},
+						},
+					},
+					Location: time.UTC,
+					BusyTimes: Intervals{
+						{
+							Start: time.Date(2022, time.February, 7, 7, 15, 0, 0, jktTime),
+							End:   time.Date(2022, time.February, 7, 8, 0, 0, 0, jktTime),
+						},
+					},
+					MaxInvitees: 1,
+				},
+			},
+			args: &args{
+				params: &GetSpotParameters{
+					Start: time.Date(2022, time.February, 7, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2022, time.February, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: []Spot{
+				{StartTime: time.Date(2022, time.February, 7, 1, 0, 0, 0, time.UTC), InviteeRemaining: 1},
+				{StartTime: time.Date(2022, time.February, 7, 1, 30, 0, 0, time.UTC), InviteeRemaining: 1},
+			},
+			wantErr: false,
+		},
+		{
+			name: "overlapping and touching ranges are merged before slotting",
+			fields: fields{
+				Event: &Event{
+					Duration: 90 * time.Minute,
+					Availability: map[time.Weekday][]Range{
+						time.Sunday: []Range{
+							{
+								StartSec: 3600,
+								EndSec:   7200,
+							},
+							{
+								StartSec: 7200,
+								EndSec:   10800,
+							},
+							{
+								StartSec: 5400,
+								EndSec:   9000,
+							},
+						},
+					},
+					Location:    time.UTC,
+					MaxInvitees: 1,
+				},
+			},
+			args: &args{
+				params: &GetSpotParameters{
+					Start: time.Date(2022, time.February, 6, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2022, time.February, 7, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: []Spot{
+				{StartTime: time.Date(2022, time.February, 6, 1, 0, 0, 0, time.UTC), InviteeRemaining: 1},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -573,3 +644,257 @@ func TestEvent_CreateBooking(t *testing.T) {
 		})
 	}
 }
+
+func TestEvent_CancelBooking(t *testing.T) {
+
+	jktTime, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	bookingID := uuid.New()
+	newEvent := func(status BookingStatus) *Event {
+		return &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 0, EndSec: 3600}},
+			},
+			Location: time.UTC,
+			Bookings: Bookings{
+				{
+					ID:        bookingID,
+					StartTime: time.Date(2022, 2, 7, 7, 0, 0, 0, jktTime),
+					Status:    status,
+				},
+			},
+			MaxInvitees: 1,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		event   *Event
+		id      uuid.UUID
+		wantErr error
+	}{
+		{
+			name:    "should cancel booking and release the spot",
+			event:   newEvent(BookingConfirmed),
+			id:      bookingID,
+			wantErr: nil,
+		},
+		{
+			name:    "should return error if booking is not found",
+			event:   newEvent(BookingConfirmed),
+			id:      uuid.New(),
+			wantErr: ErrBookingNotFound,
+		},
+		{
+			name:    "should not cancel booking twice",
+			event:   newEvent(BookingCancelled),
+			id:      bookingID,
+			wantErr: ErrBookingCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.event.CancelBooking(tt.id)
+			assert.True(t, errors.Is(err, tt.wantErr), "CancelBooking() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.Equal(t, BookingCancelled, got.Status)
+			assert.Equal(t, 1, got.Sequence)
+			assert.NotZero(t, got.CancelledAt)
+			assert.Equal(t, *got, tt.event.Bookings[0])
+
+			spots, err := tt.event.GetAvailableSpots(GetSpotParameters{
+				Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+			})
+			assert.NoError(t, err)
+			assert.Len(t, spots, 1)
+		})
+	}
+}
+
+func TestEvent_RescheduleBooking(t *testing.T) {
+
+	bookingID := uuid.New()
+	newEvent := func() *Event {
+		return &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 0, EndSec: 10800}},
+			},
+			Location: time.UTC,
+			Bookings: Bookings{
+				{
+					ID:        bookingID,
+					StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					ID:        uuid.New(),
+					StartTime: time.Date(2022, 2, 7, 2, 0, 0, 0, time.UTC),
+				},
+			},
+			MaxInvitees: 1,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		id        uuid.UUID
+		startTime time.Time
+		wantErr   error
+	}{
+		{
+			name:      "should move booking to an available spot",
+			id:        bookingID,
+			startTime: time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+			wantErr:   nil,
+		},
+		{
+			name:      "should allow to keep the same spot",
+			id:        bookingID,
+			startTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+			wantErr:   nil,
+		},
+		{
+			name:      "should not move booking to a booked spot",
+			id:        bookingID,
+			startTime: time.Date(2022, 2, 7, 2, 0, 0, 0, time.UTC),
+			wantErr:   ErrTimeNotAvailable,
+		},
+		{
+			name:      "should return error if booking is not found",
+			id:        uuid.New(),
+			startTime: time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+			wantErr:   ErrBookingNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEvent()
+			got, err := e.RescheduleBooking(tt.id, tt.startTime)
+			assert.True(t, errors.Is(err, tt.wantErr), "RescheduleBooking() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(t, got)
+				assert.Equal(t, newEvent().Bookings[0].StartTime, e.Bookings[0].StartTime)
+				return
+			}
+
+			assert.Equal(t, tt.startTime, got.StartTime)
+			assert.Equal(t, 1, got.Sequence)
+			assert.Equal(t, *got, e.Bookings[0])
+		})
+	}
+}
+
+func TestEvent_ActiveWindow(t *testing.T) {
+	newEvent := func() *Event {
+		return &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 0, EndSec: 3600}},
+			},
+			Location:    time.UTC,
+			MaxInvitees: 1,
+		}
+	}
+	params := GetSpotParameters{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name        string
+		activeFrom  time.Time
+		activeUntil time.Time
+		want        []time.Time
+	}{
+		{
+			name: "should offer spots on every day without window",
+			want: []time.Time{
+				time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 21, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:        "should offer spots within the window only",
+			activeFrom:  time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC),
+			activeUntil: time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC),
+			want:        []time.Time{time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:       "should compare dates in event location",
+			activeFrom: time.Date(2022, 2, 14, 23, 0, 0, 0, time.FixedZone("UTC-2", -2*3600)),
+			want:       []time.Time{time.Date(2022, 2, 21, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEvent()
+			e.ActiveFrom = tt.activeFrom
+			e.ActiveUntil = tt.activeUntil
+			got, err := e.GetAvailableSpots(params)
+			assert.NoError(t, err)
+			var times []time.Time
+			for _, spot := range got {
+				times = append(times, spot.StartTime)
+			}
+			assert.Equal(t, tt.want, times)
+
+			_, err = e.CreateBooking(CreateBookingParameters{
+				StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+			})
+			assert.Equal(t, e.IsActiveOn(params.Start), err == nil)
+		})
+	}
+}
+
+func TestEvent_Paused(t *testing.T) {
+	bookingID := uuid.New()
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location: time.UTC,
+		Bookings: Bookings{
+			{ID: bookingID, StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)},
+		},
+		MaxInvitees: 1,
+		Paused:      true,
+	}
+
+	spots, err := e.GetAvailableSpots(GetSpotParameters{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, spots)
+
+	_, err = e.CreateBooking(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+	})
+	assert.Equal(t, ErrEventNotAcceptingBookings, err)
+
+	_, err = e.RescheduleBooking(bookingID, time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC))
+	assert.Equal(t, ErrEventNotAcceptingBookings, err)
+
+	_, err = e.JoinWaitlist(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+	})
+	assert.Equal(t, ErrEventNotAcceptingBookings, err)
+
+	// existing bookings can still be cancelled
+	_, err = e.CancelBooking(bookingID)
+	assert.NoError(t, err)
+
+	e.Paused = false
+	_, err = e.CreateBooking(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+}
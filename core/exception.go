@@ -0,0 +1,49 @@
+package core
+
+import (
+	"time"
+)
+
+type ExceptionAction int
+
+const (
+	// RemoveRanges makes the exception's ranges unavailable. Without
+	// ranges the whole day is unavailable
+	RemoveRanges ExceptionAction = iota
+	// ReplaceRanges uses the exception's ranges instead of the weekly
+	// availability
+	ReplaceRanges
+)
+
+// AvailabilityException changes the availability of the days matching a
+// recurrence, e.g. no meetings on the first monday of every month
+type AvailabilityException struct {
+	Name string
+
+	// Start is the first day the exception applies to. It is also the
+	// reference for the recurrence interval, e.g. every other friday
+	Start time.Time
+
+	Recurrence Recurrence
+	Action     ExceptionAction
+	Ranges     []Range
+}
+
+// Matches returns true if the recurrence has an occurrence on the day
+func (a AvailabilityException) Matches(day time.Time) bool {
+	loc := day.Location()
+	dtstart := time.Date(a.Start.Year(), a.Start.Month(), a.Start.Day(), 0, 0, 0, 0, loc)
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	return len(a.Recurrence.Between(dtstart, dayStart, dayStart.AddDate(0, 0, 1))) > 0
+}
+
+// Apply returns the ranges of a matching day after the exception
+func (a AvailabilityException) Apply(ranges []Range) []Range {
+	if a.Action == ReplaceRanges {
+		return a.Ranges
+	}
+	if len(a.Ranges) == 0 {
+		return nil
+	}
+	return NewRangeSet(ranges...).Subtract(NewRangeSet(a.Ranges...))
+}
@@ -0,0 +1,165 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestAvailabilityException_Matches(t *testing.T) {
+	tests := []struct {
+		name      string
+		exception AvailabilityException
+		day       time.Time
+		want      bool
+	}{
+		{
+			name: "first monday of the month",
+			exception: AvailabilityException{
+				Start:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{Freq: Monthly, ByDay: []WeekdayNum{{N: 1, Weekday: time.Monday}}},
+			},
+			day:  time.Date(2022, 3, 7, 0, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "second monday of the month",
+			exception: AvailabilityException{
+				Start:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{Freq: Monthly, ByDay: []WeekdayNum{{N: 1, Weekday: time.Monday}}},
+			},
+			day:  time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "every other friday, on week",
+			exception: AvailabilityException{
+				Start:      time.Date(2022, 2, 4, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{Freq: Weekly, Interval: 2},
+			},
+			day:  time.Date(2022, 2, 18, 0, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "every other friday, off week",
+			exception: AvailabilityException{
+				Start:      time.Date(2022, 2, 4, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{Freq: Weekly, Interval: 2},
+			},
+			day:  time.Date(2022, 2, 11, 0, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "before the exception starts",
+			exception: AvailabilityException{
+				Start:      time.Date(2022, 2, 4, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{Freq: Weekly},
+			},
+			day:  time.Date(2022, 1, 28, 0, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "last workday of the month",
+			exception: AvailabilityException{
+				Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Recurrence: Recurrence{
+					Freq:     Monthly,
+					ByDay:    []WeekdayNum{{Weekday: time.Monday}, {Weekday: time.Tuesday}, {Weekday: time.Wednesday}, {Weekday: time.Thursday}, {Weekday: time.Friday}},
+					BySetPos: []int{-1},
+				},
+			},
+			day:  time.Date(2022, 4, 29, 0, 0, 0, 0, time.UTC),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.exception.Matches(tt.day))
+		})
+	}
+}
+
+func TestEvent_GetAvailableSpots_Exceptions(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	firstMonday := Recurrence{Freq: Monthly, ByDay: []WeekdayNum{{N: 1, Weekday: time.Monday}}}
+	params := GetSpotParameters{
+		Start: time.Date(2022, 3, 7, 0, 0, 0, 0, jakarta),
+		End:   time.Date(2022, 3, 8, 0, 0, 0, 0, jakarta),
+	}
+	tests := []struct {
+		name      string
+		exception AvailabilityException
+		want      []time.Time
+	}{
+		{
+			name: "should remove the whole day",
+			exception: AvailabilityException{
+				Name:       "all-hands",
+				Start:      time.Date(2022, 1, 1, 0, 0, 0, 0, jakarta),
+				Recurrence: firstMonday,
+				Action:     RemoveRanges,
+			},
+			want: nil,
+		},
+		{
+			name: "should remove the given ranges",
+			exception: AvailabilityException{
+				Name:       "all-hands",
+				Start:      time.Date(2022, 1, 1, 0, 0, 0, 0, jakarta),
+				Recurrence: firstMonday,
+				Action:     RemoveRanges,
+				Ranges:     []Range{{StartSec: 10 * 3600, EndSec: 11 * 3600}},
+			},
+			want: []time.Time{
+				time.Date(2022, 3, 7, 9, 0, 0, 0, jakarta),
+				time.Date(2022, 3, 7, 11, 0, 0, 0, jakarta),
+			},
+		},
+		{
+			name: "should replace the ranges",
+			exception: AvailabilityException{
+				Name:       "late shift",
+				Start:      time.Date(2022, 1, 1, 0, 0, 0, 0, jakarta),
+				Recurrence: firstMonday,
+				Action:     ReplaceRanges,
+				Ranges:     []Range{{StartSec: 15 * 3600, EndSec: 16 * 3600}},
+			},
+			want: []time.Time{time.Date(2022, 3, 7, 15, 0, 0, 0, jakarta)},
+		},
+		{
+			name: "should keep availability on days not matching",
+			exception: AvailabilityException{
+				Start:      time.Date(2022, 1, 1, 0, 0, 0, 0, jakarta),
+				Recurrence: Recurrence{Freq: Monthly, ByDay: []WeekdayNum{{N: 2, Weekday: time.Monday}}},
+			},
+			want: []time.Time{
+				time.Date(2022, 3, 7, 9, 0, 0, 0, jakarta),
+				time.Date(2022, 3, 7, 10, 0, 0, 0, jakarta),
+				time.Date(2022, 3, 7, 11, 0, 0, 0, jakarta),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Event{
+				Duration: 60 * time.Minute,
+				Availability: map[time.Weekday][]Range{
+					time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 12 * 3600}},
+				},
+				Location:    jakarta,
+				Exceptions:  []AvailabilityException{tt.exception},
+				MaxInvitees: 1,
+			}
+			got, err := e.GetAvailableSpots(params)
+			assert.NoError(t, err)
+			var times []time.Time
+			for _, spot := range got {
+				times = append(times, spot.StartTime)
+			}
+			assert.Equal(t, tt.want, times)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type FreeBusyParameters struct {
+	Start, End time.Time
+
+	// IncludeUnavailable adds the time outside of the availability of all
+	// host's events
+	IncludeUnavailable bool
+}
+
+func (p FreeBusyParameters) IsValid() error {
+	return GetSpotParameters{Start: p.Start, End: p.End}.IsValid()
+}
+
+// FreeBusy shows when a host is busy without revealing meeting details
+type FreeBusy struct {
+	Start, End time.Time
+
+	// Busy is the merged time taken by bookings and external busy times
+	Busy Intervals
+
+	// Unavailable is the merged time outside of the host's availability
+	// which is not already in Busy
+	Unavailable Intervals
+}
+
+type freeBusyInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func toFreeBusyIntervals(is Intervals) []freeBusyInterval {
+	intervals := make([]freeBusyInterval, 0, len(is))
+	for _, in := range is {
+		intervals = append(intervals, freeBusyInterval{Start: in.Start.UTC(), End: in.End.UTC()})
+	}
+	return intervals
+}
+
+// MarshalJSON renders the free busy as compact JSON with UTC times
+func (f FreeBusy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Start       time.Time          `json:"start"`
+		End         time.Time          `json:"end"`
+		Busy        []freeBusyInterval `json:"busy"`
+		Unavailable []freeBusyInterval `json:"unavailable,omitempty"`
+	}{
+		Start:       f.Start.UTC(),
+		End:         f.End.UTC(),
+		Busy:        toFreeBusyIntervals(f.Busy),
+		Unavailable: toFreeBusyIntervals(f.Unavailable),
+	})
+}
@@ -0,0 +1,142 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestHost_GetFreeBusy(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2022, 2, 7, h, m, 0, 0, time.UTC)
+	}
+
+	newHost := func() *Host {
+		chat := &Event{
+			Duration: 30 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 11 * 3600}},
+			},
+			Location:    time.UTC,
+			Bookings:    Bookings{{StartTime: at(9, 0)}, {StartTime: at(10, 0), Status: BookingCancelled}},
+			MaxInvitees: 1,
+		}
+		deepDive := &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 10 * 3600, EndSec: 12 * 3600}},
+			},
+			Location:    time.UTC,
+			Bookings:    Bookings{{StartTime: at(11, 0)}},
+			MaxInvitees: 1,
+		}
+		h := NewHost("Foo Bar", "foo@bar.com")
+		h.AddEvent(chat)
+		h.AddEvent(deepDive)
+		h.BusyTimes = Intervals{{Start: at(13, 0), End: at(14, 0)}}
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		params  FreeBusyParameters
+		want    *FreeBusy
+		wantErr bool
+	}{
+		{
+			name: "busy time from bookings of all events and external calendars",
+			params: FreeBusyParameters{
+				Start: at(0, 0),
+				End:   at(24, 0),
+			},
+			want: &FreeBusy{
+				Start: at(0, 0),
+				End:   at(24, 0),
+				Busy: Intervals{
+					{Start: at(9, 0), End: at(9, 30)},
+					{Start: at(11, 0), End: at(12, 0)},
+					{Start: at(13, 0), End: at(14, 0)},
+				},
+			},
+		},
+		{
+			name: "include unavailable hours",
+			params: FreeBusyParameters{
+				Start:              at(0, 0),
+				End:                at(24, 0),
+				IncludeUnavailable: true,
+			},
+			want: &FreeBusy{
+				Start: at(0, 0),
+				End:   at(24, 0),
+				Busy: Intervals{
+					{Start: at(9, 0), End: at(9, 30)},
+					{Start: at(11, 0), End: at(12, 0)},
+					{Start: at(13, 0), End: at(14, 0)},
+				},
+				Unavailable: Intervals{
+					{Start: at(0, 0), End: at(9, 0)},
+					{Start: at(12, 0), End: at(13, 0)},
+					{Start: at(14, 0), End: at(24, 0)},
+				},
+			},
+		},
+		{
+			name: "busy time is clipped to the window",
+			params: FreeBusyParameters{
+				Start: at(9, 15),
+				End:   at(11, 30),
+			},
+			want: &FreeBusy{
+				Start: at(9, 15),
+				End:   at(11, 30),
+				Busy: Intervals{
+					{Start: at(9, 15), End: at(9, 30)},
+					{Start: at(11, 0), End: at(11, 30)},
+				},
+			},
+		},
+		{
+			name: "return error if parameter is invalid",
+			params: FreeBusyParameters{
+				Start: at(12, 0),
+				End:   at(0, 0),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newHost().GetFreeBusy(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetFreeBusy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFreeBusy_MarshalJSON(t *testing.T) {
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+
+	fb := FreeBusy{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, jktTime),
+		End:   time.Date(2022, 2, 8, 0, 0, 0, 0, jktTime),
+		Busy: Intervals{
+			{Start: time.Date(2022, 2, 7, 9, 0, 0, 0, jktTime), End: time.Date(2022, 2, 7, 10, 0, 0, 0, jktTime)},
+		},
+	}
+
+	b, err := json.Marshal(fb)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"start": "2022-02-06T17:00:00Z",
+		"end": "2022-02-07T17:00:00Z",
+		"busy": [{"start": "2022-02-07T02:00:00Z", "end": "2022-02-07T03:00:00Z"}]
+	}`, string(b))
+}
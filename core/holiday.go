@@ -0,0 +1,246 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrUnknownHolidayCalendar = fmt.Errorf("unknown holiday calendar")
+
+// HolidayRule returns the dates of a holiday within the given year. Dates are
+// midnight in UTC
+type HolidayRule interface {
+	Dates(year int) []time.Time
+}
+
+// FixedDate is a holiday on the same date every year, e.g. Christmas
+type FixedDate struct {
+	Month time.Month
+	Day   int
+}
+
+func (r FixedDate) Dates(year int) []time.Time {
+	return []time.Time{time.Date(year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)}
+}
+
+// Observed is a fixed date holiday which is also observed on the friday
+// before when it falls on a saturday and on the monday after when it falls on
+// a sunday, as US federal holidays are. The observed day may fall in the year
+// before, e.g. new year's day on a saturday
+type Observed FixedDate
+
+func (r Observed) Dates(year int) []time.Time {
+	var dates []time.Time
+	for _, y := range []int{year, year + 1} {
+		date := time.Date(y, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+		if y == year {
+			dates = append(dates, date)
+		}
+		observed := date
+		switch date.Weekday() {
+		case time.Saturday:
+			observed = date.AddDate(0, 0, -1)
+		case time.Sunday:
+			observed = date.AddDate(0, 0, 1)
+		}
+		if !observed.Equal(date) && observed.Year() == year {
+			dates = append(dates, observed)
+		}
+	}
+	return dates
+}
+
+// EasterOffset is a holiday relative to western Easter sunday, e.g. -2 for
+// Good Friday
+type EasterOffset int
+
+func (r EasterOffset) Dates(year int) []time.Time {
+	return []time.Time{Easter(year).AddDate(0, 0, int(r))}
+}
+
+// NthWeekday is a holiday on the n-th weekday of a month, e.g. the fourth
+// thursday of november. Negative N counts from the end of the month
+type NthWeekday struct {
+	Month   time.Month
+	Weekday time.Weekday
+	N       int
+}
+
+func (r NthWeekday) Dates(year int) []time.Time {
+	if r.N < 0 {
+		last := time.Date(year, r.Month+1, 0, 0, 0, 0, 0, time.UTC)
+		back := (int(last.Weekday()) - int(r.Weekday) + 7) % 7
+		return []time.Time{last.AddDate(0, 0, -back+7*(r.N+1))}
+	}
+	first := time.Date(year, r.Month, 1, 0, 0, 0, 0, time.UTC)
+	ahead := (int(r.Weekday) - int(first.Weekday()) + 7) % 7
+	return []time.Time{first.AddDate(0, 0, ahead+7*(r.N-1))}
+}
+
+// DateList is a holiday without a rule, e.g. one following a lunar
+// calendar, whose dates are published every year
+type DateList []time.Time
+
+func (r DateList) Dates(year int) []time.Time {
+	var dates []time.Time
+	for _, d := range r {
+		if d.Year() == year {
+			dates = append(dates, time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC))
+		}
+	}
+	return dates
+}
+
+// Easter returns western Easter sunday of the year
+func Easter(year int) time.Time {
+	// anonymous gregorian algorithm
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+type Holiday struct {
+	Name string
+	Rule HolidayRule
+}
+
+// HolidayDate is a single occurrence of a holiday
+type HolidayDate struct {
+	Name string
+	Date time.Time
+}
+
+// HolidayCalendar is a set of public holidays, usually of a country.
+// Schedules subscribed to a calendar are unavailable on its holidays
+type HolidayCalendar struct {
+	Name     string
+	Holidays []Holiday
+}
+
+// NewHolidayCalendar returns the built-in calendar of the given country code,
+// e.g. "ID", "DE" or "US". Holidays without a rule can be added with Import
+func NewHolidayCalendar(country string) (*HolidayCalendar, error) {
+	holidays, ok := holidayCalendars[strings.ToUpper(country)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownHolidayCalendar, country)
+	}
+	return &HolidayCalendar{
+		Name:     strings.ToUpper(country),
+		Holidays: append([]Holiday(nil), holidays...),
+	}, nil
+}
+
+// On returns the sorted holidays of the year
+func (c HolidayCalendar) On(year int) []HolidayDate {
+	var dates []HolidayDate
+	for _, h := range c.Holidays {
+		for _, d := range h.Rule.Dates(year) {
+			dates = append(dates, HolidayDate{Name: h.Name, Date: d})
+		}
+	}
+	sort.SliceStable(dates, func(i, j int) bool {
+		return dates[i].Date.Before(dates[j].Date)
+	})
+	return dates
+}
+
+// HolidayOn returns the holiday on the date of day in its own location
+func (c HolidayCalendar) HolidayOn(day time.Time) (HolidayDate, bool) {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	for _, h := range c.Holidays {
+		for _, d := range h.Rule.Dates(day.Year()) {
+			if d.Equal(date) {
+				return HolidayDate{Name: h.Name, Date: d}, true
+			}
+		}
+	}
+	return HolidayDate{}, false
+}
+
+// Import adds holidays from CSV records of date (YYYY-MM-DD) and name, e.g.
+// "2022-05-02,Idul Fitri". Dates with the same name become a single holiday.
+// Empty lines and lines starting with # are ignored
+func (c *HolidayCalendar) Import(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	lists := map[string]DateList{}
+	var names []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return fmt.Errorf("invalid holiday date %q: %w", record[0], err)
+		}
+		name := strings.TrimSpace(record[1])
+		if _, ok := lists[name]; !ok {
+			names = append(names, name)
+		}
+		lists[name] = append(lists[name], date)
+	}
+
+	for _, name := range names {
+		c.Holidays = append(c.Holidays, Holiday{Name: name, Rule: lists[name]})
+	}
+	return nil
+}
+
+// holidayCalendars stores the rule based public holidays of each country.
+// Holidays following a lunar calendar are published yearly and need to be
+// imported
+var holidayCalendars = map[string][]Holiday{
+	"ID": {
+		{Name: "Tahun Baru Masehi", Rule: FixedDate{time.January, 1}},
+		{Name: "Wafat Isa Almasih", Rule: EasterOffset(-2)},
+		{Name: "Hari Buruh Internasional", Rule: FixedDate{time.May, 1}},
+		{Name: "Kenaikan Isa Almasih", Rule: EasterOffset(39)},
+		{Name: "Hari Lahir Pancasila", Rule: FixedDate{time.June, 1}},
+		{Name: "Hari Kemerdekaan", Rule: FixedDate{time.August, 17}},
+		{Name: "Hari Raya Natal", Rule: FixedDate{time.December, 25}},
+	},
+	"DE": {
+		{Name: "Neujahr", Rule: FixedDate{time.January, 1}},
+		{Name: "Karfreitag", Rule: EasterOffset(-2)},
+		{Name: "Ostermontag", Rule: EasterOffset(1)},
+		{Name: "Tag der Arbeit", Rule: FixedDate{time.May, 1}},
+		{Name: "Christi Himmelfahrt", Rule: EasterOffset(39)},
+		{Name: "Pfingstmontag", Rule: EasterOffset(50)},
+		{Name: "Tag der Deutschen Einheit", Rule: FixedDate{time.October, 3}},
+		{Name: "1. Weihnachtstag", Rule: FixedDate{time.December, 25}},
+		{Name: "2. Weihnachtstag", Rule: FixedDate{time.December, 26}},
+	},
+	"US": {
+		{Name: "New Year's Day", Rule: Observed{time.January, 1}},
+		{Name: "Martin Luther King Jr. Day", Rule: NthWeekday{time.January, time.Monday, 3}},
+		{Name: "Washington's Birthday", Rule: NthWeekday{time.February, time.Monday, 3}},
+		{Name: "Memorial Day", Rule: NthWeekday{time.May, time.Monday, -1}},
+		{Name: "Juneteenth", Rule: Observed{time.June, 19}},
+		{Name: "Independence Day", Rule: Observed{time.July, 4}},
+		{Name: "Labor Day", Rule: NthWeekday{time.September, time.Monday, 1}},
+		{Name: "Columbus Day", Rule: NthWeekday{time.October, time.Monday, 2}},
+		{Name: "Veterans Day", Rule: Observed{time.November, 11}},
+		{Name: "Thanksgiving Day", Rule: NthWeekday{time.November, time.Thursday, 4}},
+		{Name: "Christmas Day", Rule: Observed{time.December, 25}},
+	},
+}
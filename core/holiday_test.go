@@ -0,0 +1,206 @@
+package core_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year int
+		want time.Time
+	}{
+		{year: 2019, want: time.Date(2019, 4, 21, 0, 0, 0, 0, time.UTC)},
+		{year: 2022, want: time.Date(2022, 4, 17, 0, 0, 0, 0, time.UTC)},
+		{year: 2024, want: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{year: 2038, want: time.Date(2038, 4, 25, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Easter(tt.year), "Easter(%d)", tt.year)
+	}
+}
+
+func TestHolidayRule_Dates(t *testing.T) {
+	tests := []struct {
+		name string
+		rule HolidayRule
+		want []time.Time
+	}{
+		{
+			name: "fixed date",
+			rule: FixedDate{Month: time.August, Day: 17},
+			want: []time.Time{time.Date(2022, 8, 17, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "observed on the monday after a sunday",
+			rule: Observed{Month: time.June, Day: 19},
+			want: []time.Time{
+				time.Date(2022, 6, 19, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 6, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "observed on a weekday",
+			rule: Observed{Month: time.July, Day: 4},
+			want: []time.Time{time.Date(2022, 7, 4, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "observed on the friday before in the year before",
+			rule: Observed{Month: time.January, Day: 1},
+			want: []time.Time{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "good friday",
+			rule: EasterOffset(-2),
+			want: []time.Time{time.Date(2022, 4, 15, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "fourth thursday",
+			rule: NthWeekday{Month: time.November, Weekday: time.Thursday, N: 4},
+			want: []time.Time{time.Date(2022, 11, 24, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "first monday on the first day of month",
+			rule: NthWeekday{Month: time.August, Weekday: time.Monday, N: 1},
+			want: []time.Time{time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "last monday",
+			rule: NthWeekday{Month: time.May, Weekday: time.Monday, N: -1},
+			want: []time.Time{time.Date(2022, 5, 30, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "date list of the year only",
+			rule: DateList{
+				time.Date(2021, 5, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC),
+			},
+			want: []time.Time{
+				time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Dates(2022))
+		})
+	}
+}
+
+func TestNewHolidayCalendar(t *testing.T) {
+	us, err := NewHolidayCalendar("us")
+	assert.NoError(t, err)
+	assert.Equal(t, "US", us.Name)
+	// juneteenth and christmas day on a sunday are observed on monday
+	assert.Len(t, us.On(2022), 13)
+
+	thanksgiving, ok := us.HolidayOn(time.Date(2022, 11, 24, 15, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, "Thanksgiving Day", thanksgiving.Name)
+
+	// independence day 2026 is a saturday, new year's day 2022 too
+	for _, day := range []time.Time{
+		time.Date(2026, 7, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC),
+	} {
+		observed, ok := us.HolidayOn(day)
+		assert.True(t, ok, day)
+		assert.Equal(t, day, observed.Date)
+	}
+	_, ok = us.HolidayOn(time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	de, err := NewHolidayCalendar("DE")
+	assert.NoError(t, err)
+	assert.Equal(t, HolidayDate{Name: "Pfingstmontag", Date: time.Date(2022, 6, 6, 0, 0, 0, 0, time.UTC)}, de.On(2022)[5])
+
+	_, err = NewHolidayCalendar("XX")
+	assert.True(t, errors.Is(err, ErrUnknownHolidayCalendar))
+}
+
+func TestHolidayCalendar_Import(t *testing.T) {
+	c, err := NewHolidayCalendar("ID")
+	assert.NoError(t, err)
+
+	err = c.Import(strings.NewReader(`# published by the ministry
+2022-05-02,Idul Fitri
+2022-05-03, Idul Fitri
+2022-07-10,Idul Adha
+`))
+	assert.NoError(t, err)
+
+	h, ok := c.HolidayOn(time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, "Idul Fitri", h.Name)
+	assert.Len(t, c.On(2022), 10)
+
+	// the built-in calendar is not changed
+	other, _ := NewHolidayCalendar("ID")
+	_, ok = other.HolidayOn(time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	err = c.Import(strings.NewReader("02/05/2022,Idul Fitri\n"))
+	assert.Error(t, err)
+}
+
+func TestEvent_GetAvailableSpots_Holidays(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	id, _ := NewHolidayCalendar("ID")
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Tuesday:   []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+			time.Wednesday: []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+		},
+		Location:    jakarta,
+		Holidays:    []*HolidayCalendar{id},
+		MaxInvitees: 1,
+	}
+	params := GetSpotParameters{
+		Start: time.Date(2022, 8, 16, 0, 0, 0, 0, jakarta),
+		End:   time.Date(2022, 8, 18, 0, 0, 0, 0, jakarta),
+	}
+
+	got, err := e.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{{InviteeRemaining: 1, StartTime: time.Date(2022, 8, 16, 9, 0, 0, 0, jakarta)}}, got)
+
+	// date overrides take precedence over holidays
+	e.DateOverrides = map[int64][]Range{
+		time.Date(2022, 8, 17, 0, 0, 0, 0, jakarta).Unix(): {{StartSec: 13 * 3600, EndSec: 14 * 3600}},
+	}
+	got, err = e.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.True(t, got[1].StartTime.Equal(time.Date(2022, 8, 17, 13, 0, 0, 0, jakarta)))
+}
+
+func TestSchedule_RangesOn_Holidays(t *testing.T) {
+	de, _ := NewHolidayCalendar("DE")
+	s := Schedule{
+		Location: time.UTC,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+		},
+		Holidays: []*HolidayCalendar{de},
+	}
+	// easter monday
+	assert.True(t, s.IsHoliday(time.Date(2022, 4, 18, 0, 0, 0, 0, time.UTC)))
+	assert.Empty(t, s.RangesOn(time.Date(2022, 4, 18, 0, 0, 0, 0, time.UTC)))
+	assert.NotEmpty(t, s.RangesOn(time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC)))
+
+	// independence day 2026 observed on friday
+	us, _ := NewHolidayCalendar("US")
+	s.Availability[time.Friday] = []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}}
+	s.Holidays = []*HolidayCalendar{us}
+	assert.Empty(t, s.RangesOn(time.Date(2026, 7, 3, 0, 0, 0, 0, time.UTC)))
+	assert.NotEmpty(t, s.RangesOn(time.Date(2026, 7, 10, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func NewHost(name, email string) *Host {
+	return &Host{
+		ID:    uuid.New(),
+		Name:  name,
+		Email: email,
+	}
+}
+
+// Host is the person who owns one or more events. A host can only attend
+// one meeting at a time, regardless which of the events was booked
+type Host struct {
+	ID    uuid.UUID
+	Name  string
+	Email string
+
+	// Events stores all events owned by this host
+	Events []*Event
+
+	// BusyTimes stores time taken in the host's external calendars
+	BusyTimes Intervals
+
+	// BusySources are asked for the time taken in the host's external
+	// calendars within the window of each query
+	BusySources []BusySource
+
+	// Schedule is the host's own working hours. It limits the availability
+	// of team events the host is member of. Nil means the host follows the
+	// event's availability
+	Schedule *Schedule
+
+	// DailyCap and WeeklyCap limit the number of meetings across all of the
+	// host's events per day and per week (starting on monday) in the host's
+	// schedule location. Zero means unlimited
+	DailyCap, WeeklyCap int
+}
+
+// AddEvent assigns the event to the host
+func (h *Host) AddEvent(e *Event) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	e.Host = h
+	h.Events = append(h.Events, e)
+}
+
+// BusyIntervals returns the host's external busy times within [from, to) and
+// the time taken by bookings and held waitlist offers of all host's events
+// except the one with the given id
+func (h Host) BusyIntervals(except uuid.UUID, from, to time.Time) Intervals {
+	busy := append(Intervals(nil), h.BusyTimes...)
+	busy = append(busy, busyFrom(h.BusySources, from, to)...)
+	for _, e := range h.Events {
+		if e.ID == except {
+			continue
+		}
+		busy = append(busy, e.BookingsOf(h.ID).Intervals(e.Duration)...)
+		// offers of round robin events are not held by a specific member
+		if e.Kind != RoundRobin {
+			busy = append(busy, e.Waitlist.heldIntervals(e.Duration, e.Now())...)
+		}
+	}
+	return busy
+}
+
+// GetFreeBusy returns the host's busy time within the given window across
+// all of its events
+func (h Host) GetFreeBusy(params FreeBusyParameters) (*FreeBusy, error) {
+	if err := params.IsValid(); err != nil {
+		return nil, err
+	}
+	window := Interval{Start: params.Start, End: params.End}
+
+	busy := h.BusyIntervals(uuid.Nil, params.Start, params.End)
+	for _, e := range h.Events {
+		busy = append(busy, e.BusyTimes...)
+		busy = append(busy, busyFrom(e.BusySources, params.Start, params.End)...)
+	}
+	busy = busy.Clip(window).Merge()
+
+	fb := &FreeBusy{
+		Start: params.Start,
+		End:   params.End,
+		Busy:  busy,
+	}
+
+	if params.IncludeUnavailable {
+		var available Intervals
+		for _, e := range h.Events {
+			available = append(available, e.AvailableIntervals(params.Start, params.End)...)
+		}
+		fb.Unavailable = Intervals{window}.Subtract(available).Subtract(busy)
+	}
+	return fb, nil
+}
@@ -0,0 +1,148 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestHost_AddEvent(t *testing.T) {
+	h := NewHost("Foo Bar", "foo@bar.com")
+	e := &Event{}
+
+	h.AddEvent(e)
+
+	assert.NotEmpty(t, e.ID)
+	assert.Equal(t, h, e.Host)
+	assert.Len(t, h.Events, 1)
+}
+
+func TestHost_CrossEventConflict(t *testing.T) {
+
+	jktTime, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	chat := &Event{
+		Name:     "30 min chat",
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+	}
+	deepDive := &Event{
+		Name:     "60 min deep dive",
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+	}
+
+	h := NewHost("Foo Bar", "foo@bar.com")
+	h.AddEvent(chat)
+	h.AddEvent(deepDive)
+
+	_, err = deepDive.CreateBooking(CreateBookingParameters{
+		Invitee: Invitee{
+			Email:    "bar@foo.com",
+			Name:     "Bar Foo",
+			Timezone: jktTime,
+		},
+		StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		event *Event
+		want  []time.Time
+	}{
+		{
+			name:  "booking on other event blocks overlapping spots",
+			event: chat,
+			want: []time.Time{
+				time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 7, 1, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "booking on the same event only reduce its own spot",
+			event: deepDive,
+			want: []time.Time{
+				time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spots, err := tt.event.GetAvailableSpots(GetSpotParameters{
+				Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+			})
+			assert.NoError(t, err)
+
+			var got []time.Time
+			for _, s := range spots {
+				got = append(got, s.StartTime)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = chat.CreateBooking(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 7, 0, 30, 0, 0, time.UTC),
+	})
+	assert.True(t, errors.Is(err, ErrTimeNotAvailable))
+}
+
+// weeklyBusy is busy for the first hour of every monday, without an end
+type weeklyBusy struct {
+	windows []Interval
+}
+
+func (w *weeklyBusy) BusyIntervals(from, to time.Time) Intervals {
+	w.windows = append(w.windows, Interval{Start: from, End: to})
+	var busy Intervals
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Monday {
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+			busy = append(busy, Interval{Start: start, End: start.Add(time.Hour)})
+		}
+	}
+	return busy
+}
+
+func TestEvent_BusySources(t *testing.T) {
+	source := &weeklyBusy{}
+	e := &Event{
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		BusySources: []BusySource{source},
+	}
+	h := NewHost("Foo Bar", "foo@bar.com")
+	h.AddEvent(e)
+
+	monday := time.Date(2040, 1, 2, 0, 0, 0, 0, time.UTC)
+	spots, err := e.GetAvailableSpots(GetSpotParameters{Start: monday, End: monday.Add(12 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{
+		{StartTime: monday.Add(time.Hour), InviteeRemaining: 1},
+		{StartTime: monday.Add(90 * time.Minute), InviteeRemaining: 1},
+	}, spots)
+	assert.Equal(t, []Interval{{Start: monday, End: monday.AddDate(0, 0, 1)}}, source.windows)
+
+	fb, err := h.GetFreeBusy(FreeBusyParameters{Start: monday, End: monday.AddDate(0, 0, 7)})
+	assert.NoError(t, err)
+	assert.Equal(t, Intervals{{Start: monday, End: monday.Add(time.Hour)}}, fb.Busy)
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is an absolute time span [Start, End)
+type Interval struct {
+	Start, End time.Time
+}
+
+// Overlaps returns true if both intervals share at least one instant
+func (i Interval) Overlaps(o Interval) bool {
+	return i.Start.Before(o.End) && o.Start.Before(i.End)
+}
+
+type Intervals []Interval
+
+// BusySource tells the time taken outside of this application within
+// [from, to), e.g. an imported calendar whose recurring events have no end
+type BusySource interface {
+	BusyIntervals(from, to time.Time) Intervals
+}
+
+// busyFrom returns the busy time of all sources within [from, to)
+func busyFrom(sources []BusySource, from, to time.Time) Intervals {
+	var busy Intervals
+	for _, s := range sources {
+		busy = append(busy, s.BusyIntervals(from, to)...)
+	}
+	return busy
+}
+
+// Overlaps returns true if any of the intervals overlaps with i
+func (is Intervals) Overlaps(i Interval) bool {
+	for _, in := range is {
+		if in.Overlaps(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge returns sorted intervals where overlapping or adjacent intervals
+// are joined together
+func (is Intervals) Merge() Intervals {
+	if len(is) == 0 {
+		return nil
+	}
+
+	sorted := make(Intervals, len(is))
+	copy(sorted, is)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	merged := Intervals{sorted[0]}
+	for _, in := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if in.Start.After(last.End) {
+			merged = append(merged, in)
+			continue
+		}
+		if in.End.After(last.End) {
+			last.End = in.End
+		}
+	}
+	return merged
+}
+
+// Clip returns the intervals cut to fit within the window, dropping the ones
+// outside of it
+func (is Intervals) Clip(window Interval) Intervals {
+	var clipped Intervals
+	for _, in := range is {
+		if !in.Overlaps(window) {
+			continue
+		}
+		if in.Start.Before(window.Start) {
+			in.Start = window.Start
+		}
+		if in.End.After(window.End) {
+			in.End = window.End
+		}
+		clipped = append(clipped, in)
+	}
+	return clipped
+}
+
+// Subtract returns the merged intervals with all time in other removed
+func (is Intervals) Subtract(other Intervals) Intervals {
+	var result Intervals
+	other = other.Merge()
+	for _, in := range is.Merge() {
+		for _, o := range other {
+			if !o.Overlaps(in) {
+				continue
+			}
+			if o.Start.After(in.Start) {
+				result = append(result, Interval{Start: in.Start, End: o.Start})
+			}
+			in.Start = o.End
+			if !in.Start.Before(in.End) {
+				break
+			}
+		}
+		if in.Start.Before(in.End) {
+			result = append(result, in)
+		}
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestInterval_Overlaps(t *testing.T) {
+	at := func(h int) time.Time {
+		return time.Date(2022, 2, 7, h, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		a, b Interval
+		want bool
+	}{
+		{
+			name: "partially overlap",
+			a:    Interval{Start: at(1), End: at(3)},
+			b:    Interval{Start: at(2), End: at(4)},
+			want: true,
+		},
+		{
+			name: "contained",
+			a:    Interval{Start: at(1), End: at(4)},
+			b:    Interval{Start: at(2), End: at(3)},
+			want: true,
+		},
+		{
+			name: "adjacent intervals do not overlap",
+			a:    Interval{Start: at(1), End: at(2)},
+			b:    Interval{Start: at(2), End: at(3)},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.a.Overlaps(tt.b))
+			assert.Equal(t, tt.want, tt.b.Overlaps(tt.a))
+		})
+	}
+}
+
+func TestIntervals_Merge(t *testing.T) {
+	at := func(h int) time.Time {
+		return time.Date(2022, 2, 7, h, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		is   Intervals
+		want Intervals
+	}{
+		{
+			name: "empty",
+			is:   nil,
+			want: nil,
+		},
+		{
+			name: "unsorted overlapping and adjacent intervals are joined",
+			is: Intervals{
+				{Start: at(5), End: at(6)},
+				{Start: at(2), End: at(3)},
+				{Start: at(1), End: at(2)},
+				{Start: at(2), End: at(4)},
+			},
+			want: Intervals{
+				{Start: at(1), End: at(4)},
+				{Start: at(5), End: at(6)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.is.Merge())
+		})
+	}
+}
+
+func TestIntervals_Clip(t *testing.T) {
+	at := func(h int) time.Time {
+		return time.Date(2022, 2, 7, h, 0, 0, 0, time.UTC)
+	}
+	is := Intervals{
+		{Start: at(0), End: at(2)},
+		{Start: at(3), End: at(4)},
+		{Start: at(5), End: at(8)},
+		{Start: at(9), End: at(10)},
+	}
+	assert.Equal(t, Intervals{
+		{Start: at(1), End: at(2)},
+		{Start: at(3), End: at(4)},
+		{Start: at(5), End: at(6)},
+	}, is.Clip(Interval{Start: at(1), End: at(6)}))
+}
+
+func TestIntervals_Subtract(t *testing.T) {
+	at := func(h int) time.Time {
+		return time.Date(2022, 2, 7, h, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		is    Intervals
+		other Intervals
+		want  Intervals
+	}{
+		{
+			name:  "nothing to subtract",
+			is:    Intervals{{Start: at(1), End: at(3)}},
+			other: nil,
+			want:  Intervals{{Start: at(1), End: at(3)}},
+		},
+		{
+			name:  "split in the middle",
+			is:    Intervals{{Start: at(1), End: at(5)}},
+			other: Intervals{{Start: at(2), End: at(3)}},
+			want:  Intervals{{Start: at(1), End: at(2)}, {Start: at(3), End: at(5)}},
+		},
+		{
+			name:  "remove both ends and whole intervals",
+			is:    Intervals{{Start: at(1), End: at(5)}, {Start: at(6), End: at(7)}, {Start: at(8), End: at(10)}},
+			other: Intervals{{Start: at(0), End: at(2)}, {Start: at(4), End: at(7)}, {Start: at(9), End: at(11)}},
+			want:  Intervals{{Start: at(2), End: at(4)}, {Start: at(8), End: at(9)}},
+		},
+		{
+			name:  "remove everything",
+			is:    Intervals{{Start: at(1), End: at(5)}},
+			other: Intervals{{Start: at(1), End: at(5)}},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.is.Subtract(tt.other))
+		})
+	}
+}
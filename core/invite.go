@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -9,3 +10,34 @@ type Invitee struct {
 	Name     string
 	Timezone *time.Location
 }
+
+type invitee struct {
+	Email    string
+	Name     string
+	Timezone string `json:",omitempty"`
+}
+
+// MarshalJSON stores the timezone by name, e.g. Asia/Jakarta
+func (i Invitee) MarshalJSON() ([]byte, error) {
+	s := invitee{Email: i.Email, Name: i.Name}
+	if i.Timezone != nil {
+		s.Timezone = i.Timezone.String()
+	}
+	return json.Marshal(s)
+}
+
+func (i *Invitee) UnmarshalJSON(data []byte) error {
+	var s invitee
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*i = Invitee{Email: s.Email, Name: s.Name}
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return err
+		}
+		i.Timezone = loc
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package core
+
+// Journal is the stream the booking changes of an event are stored in, e.g.
+// ledger.Ledger. Changes are appended before they are applied to
+// Event.Bookings, so that the bookings can be rebuilt from the stream. A
+// change which can not be appended is not made
+type Journal interface {
+	// Append stores the domain events of the changes, either all of them or
+	// none
+	Append(ds ...DomainEvent) error
+}
+
+// journal appends the changes to the event's journal
+func (e *Event) journal(changes ...BookingChange) error {
+	if e.Journal == nil || len(changes) == 0 {
+		return nil
+	}
+	ds := make([]DomainEvent, 0, len(changes))
+	for _, c := range changes {
+		ds = append(ds, e.bookingEvent(c))
+	}
+	return e.Journal.Append(ds...)
+}
@@ -0,0 +1,69 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+type recordingJournal struct {
+	appends [][]DomainEvent
+	err     error
+}
+
+func (j *recordingJournal) Append(ds ...DomainEvent) error {
+	if j.err != nil {
+		return j.err
+	}
+	j.appends = append(j.appends, ds)
+	return nil
+}
+
+func TestEvent_Journal(t *testing.T) {
+	journal := &recordingJournal{}
+	publisher := &recordingPublisher{}
+	e := &Event{
+		ID:       uuid.New(),
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Publisher:   publisher,
+		Journal:     journal,
+	}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	b, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	series, err := e.CreateSeries(CreateSeriesParameters{
+		StartTime:  slot.Add(time.Hour),
+		Recurrence: Recurrence{Freq: Weekly, Count: 2},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, journal.appends, 2)
+	assert.Len(t, journal.appends[1], 2, "series is appended at once")
+
+	// changes which can not be journaled are not made
+	journal.err = fmt.Errorf("disk full")
+	_, err = e.CreateBooking(CreateBookingParameters{StartTime: slot.AddDate(0, 0, 14)})
+	assert.Equal(t, journal.err, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.Equal(t, journal.err, err)
+	_, err = e.RescheduleBooking(b.ID, slot.AddDate(0, 0, 14))
+	assert.Equal(t, journal.err, err)
+	_, err = e.RescheduleSeries(series.ID, slot, slot.AddDate(0, 0, 14))
+	assert.Equal(t, journal.err, err)
+
+	assert.Len(t, e.Bookings, 3)
+	assert.Equal(t, BookingConfirmed, e.Bookings[0].Status)
+	assert.True(t, slot.Equal(e.Bookings[0].StartTime))
+	assert.True(t, slot.Add(time.Hour).Equal(e.SeriesBookings(series.ID)[0].StartTime))
+	assert.Equal(t, []string{"booking.created", "booking.created", "booking.created"}, publisher.Topics())
+}
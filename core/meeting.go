@@ -0,0 +1,98 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidMeetingLocation = fmt.Errorf("invalid meeting location")
+
+// MeetingLocationKind defines where the meeting happens. It is different from
+// the event's Location which is only its timezone
+type MeetingLocationKind int
+
+const (
+	InPerson MeetingLocationKind = iota + 1
+	// PhoneCallByHost means the host calls the invitee on the number given
+	// when booking
+	PhoneCallByHost
+	// PhoneCallByInvitee means the invitee calls the host's number
+	PhoneCallByInvitee
+	VideoLink
+	CustomLocation
+)
+
+var meetingLocationKindNames = map[MeetingLocationKind]string{
+	InPerson:           "in_person",
+	PhoneCallByHost:    "phone_call_by_host",
+	PhoneCallByInvitee: "phone_call_by_invitee",
+	VideoLink:          "video_link",
+	CustomLocation:     "custom",
+}
+
+func (k MeetingLocationKind) String() string {
+	if name, ok := meetingLocationKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MeetingLocation is a place the meeting can happen. Value is the address,
+// the phone number, the video link or a free text depending on the kind.
+// The value of PhoneCallByHost is given by the invitee
+type MeetingLocation struct {
+	Kind  MeetingLocationKind
+	Value string
+}
+
+// IsZero returns true if no meeting location is set
+func (l MeetingLocation) IsZero() bool {
+	return l.Kind == 0
+}
+
+// String returns the text shown to the invitee and the host, e.g. in
+// notifications and calendar invites
+func (l MeetingLocation) String() string {
+	switch l.Kind {
+	case PhoneCallByHost:
+		return "Phone call, the host will call " + l.Value
+	case PhoneCallByInvitee:
+		return "Phone call to " + l.Value
+	default:
+		return l.Value
+	}
+}
+
+// chooseMeetingLocation returns the location chosen by the invitee among
+// the event's options. Without choice the only option is used
+func (e Event) chooseMeetingLocation(choice MeetingLocation) (MeetingLocation, error) {
+	if len(e.MeetingLocations) == 0 {
+		if !choice.IsZero() {
+			return MeetingLocation{}, fmt.Errorf("%w: event has no meeting location options", ErrInvalidMeetingLocation)
+		}
+		return MeetingLocation{}, nil
+	}
+	if choice.IsZero() {
+		if len(e.MeetingLocations) > 1 {
+			return MeetingLocation{}, fmt.Errorf("%w: a meeting location must be chosen", ErrInvalidMeetingLocation)
+		}
+		choice = e.MeetingLocations[0]
+	}
+
+	for _, option := range e.MeetingLocations {
+		if option.Kind != choice.Kind {
+			continue
+		}
+		if option.Kind == PhoneCallByHost {
+			phone := strings.TrimSpace(choice.Value)
+			if err := (Question{Type: PhoneQuestion, Required: true}).validate([]string{phone}); err != nil {
+				return MeetingLocation{}, fmt.Errorf("%w: invitee phone number %s", ErrInvalidMeetingLocation, err)
+			}
+			return MeetingLocation{Kind: PhoneCallByHost, Value: phone}, nil
+		}
+		if option.Value == choice.Value {
+			return option, nil
+		}
+	}
+	return MeetingLocation{}, fmt.Errorf("%w: %s is not an option", ErrInvalidMeetingLocation, choice.Kind)
+}
@@ -0,0 +1,101 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestEvent_CreateBooking_MeetingLocation(t *testing.T) {
+	office := MeetingLocation{Kind: InPerson, Value: "Jl. Sudirman 1, Jakarta"}
+	video := MeetingLocation{Kind: VideoLink, Value: "https://meet.example.com/abc"}
+	hostCalls := MeetingLocation{Kind: PhoneCallByHost}
+
+	tests := []struct {
+		name    string
+		options []MeetingLocation
+		choice  MeetingLocation
+		want    MeetingLocation
+		wantErr error
+	}{
+		{
+			name:    "should store the chosen location",
+			options: []MeetingLocation{office, video},
+			choice:  video,
+			want:    video,
+		},
+		{
+			name:    "should use the only option without choice",
+			options: []MeetingLocation{office},
+			want:    office,
+		},
+		{
+			name:    "should require a choice among many options",
+			options: []MeetingLocation{office, video},
+			wantErr: ErrInvalidMeetingLocation,
+		},
+		{
+			name:    "should reject a location which is not an option",
+			options: []MeetingLocation{office},
+			choice:  MeetingLocation{Kind: InPerson, Value: "my house"},
+			wantErr: ErrInvalidMeetingLocation,
+		},
+		{
+			name:    "should store the invitee phone number when host calls",
+			options: []MeetingLocation{office, hostCalls},
+			choice:  MeetingLocation{Kind: PhoneCallByHost, Value: " +62 812 3456 7890 "},
+			want:    MeetingLocation{Kind: PhoneCallByHost, Value: "+62 812 3456 7890"},
+		},
+		{
+			name:    "should require the invitee phone number when host calls",
+			options: []MeetingLocation{hostCalls},
+			wantErr: ErrInvalidMeetingLocation,
+		},
+		{
+			name: "should allow no location if the event has no options",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Event{
+				Duration: 60 * time.Minute,
+				Availability: map[time.Weekday][]Range{
+					time.Monday: []Range{{StartSec: 0, EndSec: 3600}},
+				},
+				Location:         time.UTC,
+				MaxInvitees:      1,
+				MeetingLocations: tt.options,
+			}
+			got, err := e.CreateBooking(CreateBookingParameters{
+				StartTime:       time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				MeetingLocation: tt.choice,
+			})
+			assert.True(t, errors.Is(err, tt.wantErr), "CreateBooking() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got.MeetingLocation)
+		})
+	}
+}
+
+func TestMeetingLocation_String(t *testing.T) {
+	tests := []struct {
+		location MeetingLocation
+		want     string
+	}{
+		{location: MeetingLocation{Kind: InPerson, Value: "Jl. Sudirman 1"}, want: "Jl. Sudirman 1"},
+		{location: MeetingLocation{Kind: PhoneCallByHost, Value: "+628123456789"}, want: "Phone call, the host will call +628123456789"},
+		{location: MeetingLocation{Kind: PhoneCallByInvitee, Value: "+498912345"}, want: "Phone call to +498912345"},
+		{location: MeetingLocation{Kind: VideoLink, Value: "https://meet.example.com/abc"}, want: "https://meet.example.com/abc"},
+		{location: MeetingLocation{Kind: CustomLocation, Value: "Booth 12, hall B"}, want: "Booth 12, hall B"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.location.String())
+	}
+}
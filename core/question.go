@@ -0,0 +1,150 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidAnswer = fmt.Errorf("invalid answer")
+
+type QuestionType int
+
+const (
+	TextQuestion QuestionType = iota
+	LongTextQuestion
+	SingleChoiceQuestion
+	MultipleChoiceQuestion
+	PhoneQuestion
+	// CheckboxQuestion is answered with "true" or "false". A required
+	// checkbox must be checked, e.g. to accept terms
+	CheckboxQuestion
+)
+
+var questionTypeNames = map[QuestionType]string{
+	TextQuestion:           "text",
+	LongTextQuestion:       "long_text",
+	SingleChoiceQuestion:   "single_choice",
+	MultipleChoiceQuestion: "multiple_choice",
+	PhoneQuestion:          "phone",
+	CheckboxQuestion:       "checkbox",
+}
+
+func (t QuestionType) String() string {
+	if name, ok := questionTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+const (
+	maxTextLength     = 255
+	maxLongTextLength = 5000
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{6,15}$`)
+
+// Question is asked to the invitee when booking
+type Question struct {
+	ID       string
+	Label    string
+	Type     QuestionType
+	Required bool
+
+	// Options are the choices of single and multiple choice questions
+	Options []string
+}
+
+type Questions []Question
+
+// Answers stores the answers of a booking keyed by question id. Only
+// multiple choice questions may have more than one value
+type Answers map[string][]string
+
+// Validate checks the answers against the questions and returns them with
+// surrounding spaces and empty values removed
+func (qs Questions) Validate(answers Answers) (Answers, error) {
+	known := map[string]bool{}
+	for _, q := range qs {
+		known[q.ID] = true
+	}
+	for id := range answers {
+		if !known[id] {
+			return nil, fmt.Errorf("%w: unknown question %s", ErrInvalidAnswer, id)
+		}
+	}
+
+	var valid Answers
+	for _, q := range qs {
+		var values []string
+		for _, v := range answers[q.ID] {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		if err := q.validate(values); err != nil {
+			return nil, fmt.Errorf("%w: %s %s", ErrInvalidAnswer, q.ID, err)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if valid == nil {
+			valid = Answers{}
+		}
+		valid[q.ID] = values
+	}
+	return valid, nil
+}
+
+func (q Question) validate(values []string) error {
+	if len(values) == 0 {
+		if q.Required {
+			return fmt.Errorf("is required")
+		}
+		return nil
+	}
+	if len(values) > 1 && q.Type != MultipleChoiceQuestion {
+		return fmt.Errorf("must have a single value")
+	}
+
+	switch q.Type {
+	case TextQuestion:
+		if utf8.RuneCountInString(values[0]) > maxTextLength {
+			return fmt.Errorf("must not be longer than %d characters", maxTextLength)
+		}
+	case LongTextQuestion:
+		if utf8.RuneCountInString(values[0]) > maxLongTextLength {
+			return fmt.Errorf("must not be longer than %d characters", maxLongTextLength)
+		}
+	case SingleChoiceQuestion, MultipleChoiceQuestion:
+		for _, v := range values {
+			if !q.hasOption(v) {
+				return fmt.Errorf("has unknown option %q", v)
+			}
+		}
+	case PhoneQuestion:
+		phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(values[0])
+		if !phonePattern.MatchString(phone) {
+			return fmt.Errorf("is not a phone number")
+		}
+	case CheckboxQuestion:
+		if values[0] != "true" && values[0] != "false" {
+			return fmt.Errorf("must be true or false")
+		}
+		if q.Required && values[0] != "true" {
+			return fmt.Errorf("must be checked")
+		}
+	}
+	return nil
+}
+
+func (q Question) hasOption(v string) bool {
+	for _, o := range q.Options {
+		if o == v {
+			return true
+		}
+	}
+	return false
+}
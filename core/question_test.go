@@ -0,0 +1,126 @@
+package core_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestQuestions_Validate(t *testing.T) {
+	questions := Questions{
+		{ID: "topic", Label: "What do you want to discuss?", Type: LongTextQuestion, Required: true},
+		{ID: "company", Label: "Company", Type: TextQuestion},
+		{ID: "size", Label: "Company size", Type: SingleChoiceQuestion, Options: []string{"1-10", "11-50", "50+"}},
+		{ID: "products", Label: "Products", Type: MultipleChoiceQuestion, Options: []string{"cloud", "mobile", "web"}},
+		{ID: "phone", Label: "Phone number", Type: PhoneQuestion},
+		{ID: "terms", Label: "I accept the terms", Type: CheckboxQuestion, Required: true},
+	}
+	tests := []struct {
+		name    string
+		answers Answers
+		want    Answers
+		wantErr bool
+	}{
+		{
+			name: "should accept valid answers",
+			answers: Answers{
+				"topic":    {" pricing "},
+				"size":     {"11-50"},
+				"products": {"cloud", "web"},
+				"phone":    {"+62 (812) 3456-7890"},
+				"terms":    {"true"},
+			},
+			want: Answers{
+				"topic":    {"pricing"},
+				"size":     {"11-50"},
+				"products": {"cloud", "web"},
+				"phone":    {"+62 (812) 3456-7890"},
+				"terms":    {"true"},
+			},
+		},
+		{
+			name:    "should drop empty optional answers",
+			answers: Answers{"topic": {"pricing"}, "company": {"  "}, "terms": {"true"}},
+			want:    Answers{"topic": {"pricing"}, "terms": {"true"}},
+		},
+		{
+			name:    "should reject missing required answer",
+			answers: Answers{"terms": {"true"}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject unchecked required checkbox",
+			answers: Answers{"topic": {"pricing"}, "terms": {"false"}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject unknown option",
+			answers: Answers{"topic": {"pricing"}, "terms": {"true"}, "size": {"1000+"}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject multiple values of single choice",
+			answers: Answers{"topic": {"pricing"}, "terms": {"true"}, "size": {"1-10", "11-50"}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject malformed phone number",
+			answers: Answers{"topic": {"pricing"}, "terms": {"true"}, "phone": {"call me"}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject too long text",
+			answers: Answers{"topic": {"pricing"}, "terms": {"true"}, "company": {strings.Repeat("a", 256)}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject answer of unknown question",
+			answers: Answers{"topic": {"pricing"}, "terms": {"true"}, "age": {"30"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := questions.Validate(tt.answers)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidAnswer), "Validate() error = %v", err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvent_CreateBooking_Answers(t *testing.T) {
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Questions: Questions{
+			{ID: "topic", Label: "What do you want to discuss?", Type: TextQuestion, Required: true},
+		},
+	}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	_, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.True(t, errors.Is(err, ErrInvalidAnswer))
+	assert.Empty(t, e.Bookings)
+
+	b, err := e.CreateBooking(CreateBookingParameters{
+		StartTime: slot,
+		Answers:   Answers{"topic": {"pricing "}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Answers{"topic": {"pricing"}}, b.Answers)
+	assert.Equal(t, b.Answers, e.Bookings[0].Answers)
+}
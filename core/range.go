@@ -55,6 +55,15 @@ func (r Range) intToString(s int) string {
     return t.Format("15:04")
 }
 
+// Interval returns the absolute time of the range on the given date
+func (r Range) Interval(date time.Time) Interval {
+    cur := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+    return Interval{
+        Start: cur.Add(time.Duration(r.StartSec) * time.Second),
+        End:   cur.Add(time.Duration(r.EndSec) * time.Second),
+    }
+}
+
 // Slots return all start time that is available for the range [startTime, endTime)
 func (r Range) Slots(date time.Time, duration time.Duration) []time.Time {
     cur := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
@@ -67,8 +76,8 @@ func (r Range) Slots(date time.Time, duration time.Duration) []time.Time {
         if curr == end || curr.After(end) {
             break
         }
-        end := curr.Add(duration)
-        if end == end || end.Before(end) {
+        slotEnd := curr.Add(duration)
+        if slotEnd == end || slotEnd.Before(end) {
             availabilities = append(availabilities, curr)
         }
         curr = curr.Add(duration)
@@ -101,6 +101,20 @@ func TestRange_Slots(t *testing.T) {
 				time.Date(2022, time.February, 7, 1, 30, 0, 0, time.UTC),
 			},
 		},
+		{
+			name: "should not return time when the duration does not fit the rest of the range",
+			fields: fields{
+				StartSec: 0,
+				EndSec:   5400,
+			},
+			args: args{
+				date:     time.Date(2022, time.February, 7, 10, 0, 0, 0, time.UTC),
+				duration: 60 * time.Minute,
+			},
+			want: []time.Time{
+				time.Date(2022, time.February, 7, 0, 0, 0, 0, time.UTC),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// RangeSet is a normalized set of ranges within a day: sorted, without
+// empty ranges and without ranges overlapping or touching each other
+type RangeSet []Range
+
+// NewRangeSet returns the normalized set of the given ranges
+func NewRangeSet(ranges ...Range) RangeSet {
+	var valid []Range
+	for _, r := range ranges {
+		if r.StartSec < r.EndSec {
+			valid = append(valid, r)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i].StartSec < valid[j].StartSec
+	})
+
+	set := RangeSet{valid[0]}
+	for _, r := range valid[1:] {
+		last := &set[len(set)-1]
+		if r.StartSec > last.EndSec {
+			set = append(set, r)
+			continue
+		}
+		if r.EndSec > last.EndSec {
+			last.EndSec = r.EndSec
+		}
+	}
+	return set
+}
+
+// Union returns the ranges covered by either set
+func (s RangeSet) Union(o RangeSet) RangeSet {
+	return NewRangeSet(append(append([]Range(nil), s...), o...)...)
+}
+
+// Intersect returns the ranges covered by both sets
+func (s RangeSet) Intersect(o RangeSet) RangeSet {
+	var ranges []Range
+	for _, a := range s {
+		for _, b := range o {
+			start, end := a.StartSec, a.EndSec
+			if b.StartSec > start {
+				start = b.StartSec
+			}
+			if b.EndSec < end {
+				end = b.EndSec
+			}
+			if start < end {
+				ranges = append(ranges, Range{StartSec: start, EndSec: end})
+			}
+		}
+	}
+	return NewRangeSet(ranges...)
+}
+
+// Subtract returns the ranges of s which are not covered by o
+func (s RangeSet) Subtract(o RangeSet) RangeSet {
+	o = NewRangeSet(o...)
+
+	var ranges []Range
+	for _, r := range s {
+		for _, b := range o {
+			if b.EndSec <= r.StartSec || b.StartSec >= r.EndSec {
+				continue
+			}
+			if b.StartSec > r.StartSec {
+				ranges = append(ranges, Range{StartSec: r.StartSec, EndSec: b.StartSec})
+			}
+			r.StartSec = b.EndSec
+		}
+		if r.StartSec < r.EndSec {
+			ranges = append(ranges, r)
+		}
+	}
+	return NewRangeSet(ranges...)
+}
+
+// Contains returns true if the whole range is covered by the set
+func (s RangeSet) Contains(r Range) bool {
+	for _, sr := range s {
+		if sr.StartSec <= r.StartSec && r.EndSec <= sr.EndSec {
+			return true
+		}
+	}
+	return false
+}
+
+// Slots return all start time on the given date for which the whole
+// duration fits within one of the ranges
+func (s RangeSet) Slots(date time.Time, duration time.Duration) []time.Time {
+	var slots []time.Time
+	for _, r := range s {
+		slots = append(slots, r.Slots(date, duration)...)
+	}
+	return slots
+}
+
+// RangesOn returns the part of the intervals which falls on the given date,
+// in seconds since midnight of the date's location
+func (is Intervals) RangesOn(date time.Time) RangeSet {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	var ranges []Range
+	for _, in := range is.Clip(Interval{Start: dayStart, End: dayEnd}) {
+		ranges = append(ranges, Range{
+			StartSec: int(in.Start.Sub(dayStart) / time.Second),
+			EndSec:   int((in.End.Sub(dayStart) + time.Second - 1) / time.Second),
+		})
+	}
+	return NewRangeSet(ranges...)
+}
@@ -0,0 +1,146 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func hours(pairs ...int) RangeSet {
+	var s RangeSet
+	for i := 0; i < len(pairs); i += 2 {
+		s = append(s, Range{StartSec: pairs[i] * 3600, EndSec: pairs[i+1] * 3600})
+	}
+	return s
+}
+
+func TestNewRangeSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []Range
+		want   RangeSet
+	}{
+		{
+			name:   "empty",
+			ranges: nil,
+			want:   nil,
+		},
+		{
+			name:   "touching ranges are merged",
+			ranges: hours(1, 2, 2, 3),
+			want:   hours(1, 3),
+		},
+		{
+			name:   "unsorted overlapping ranges are merged and empty ranges dropped",
+			ranges: hours(5, 6, 1, 3, 2, 4, 7, 7),
+			want:   hours(1, 4, 5, 6),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewRangeSet(tt.ranges...))
+		})
+	}
+}
+
+func TestRangeSet_Operations(t *testing.T) {
+	tests := []struct {
+		name          string
+		a, b          RangeSet
+		wantUnion     RangeSet
+		wantIntersect RangeSet
+		wantSubtract  RangeSet
+	}{
+		{
+			name:          "disjoint",
+			a:             hours(1, 2),
+			b:             hours(3, 4),
+			wantUnion:     hours(1, 2, 3, 4),
+			wantIntersect: nil,
+			wantSubtract:  hours(1, 2),
+		},
+		{
+			name:          "partially overlap",
+			a:             hours(1, 3),
+			b:             hours(2, 4),
+			wantUnion:     hours(1, 4),
+			wantIntersect: hours(2, 3),
+			wantSubtract:  hours(1, 2),
+		},
+		{
+			name:          "multiple ranges",
+			a:             hours(9, 12, 13, 17),
+			b:             hours(10, 11, 12, 14, 16, 18),
+			wantUnion:     hours(9, 18),
+			wantIntersect: hours(10, 11, 13, 14, 16, 17),
+			wantSubtract:  hours(9, 10, 11, 12, 14, 16),
+		},
+		{
+			name:          "subtract everything",
+			a:             hours(9, 12),
+			b:             hours(0, 24),
+			wantUnion:     hours(0, 24),
+			wantIntersect: hours(9, 12),
+			wantSubtract:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantUnion, tt.a.Union(tt.b))
+			assert.Equal(t, tt.wantIntersect, tt.a.Intersect(tt.b))
+			assert.Equal(t, tt.wantSubtract, tt.a.Subtract(tt.b))
+		})
+	}
+}
+
+func TestRangeSet_Contains(t *testing.T) {
+	s := hours(9, 12, 13, 17)
+
+	assert.True(t, s.Contains(Range{StartSec: 9 * 3600, EndSec: 10 * 3600}))
+	assert.True(t, s.Contains(Range{StartSec: 13 * 3600, EndSec: 17 * 3600}))
+	assert.False(t, s.Contains(Range{StartSec: 11 * 3600, EndSec: 14 * 3600}))
+	assert.False(t, s.Contains(Range{StartSec: 17 * 3600, EndSec: 18 * 3600}))
+}
+
+func TestRangeSet_Slots(t *testing.T) {
+	date := time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC)
+	s := NewRangeSet(hours(1, 2, 2, 3, 5, 6)...)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 7, 2, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 7, 5, 0, 0, 0, time.UTC),
+	}, s.Slots(date, 60*time.Minute))
+
+	assert.Equal(t, []time.Time{
+		time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+	}, s.Slots(date, 90*time.Minute))
+}
+
+func TestIntervals_RangesOn(t *testing.T) {
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+
+	is := Intervals{
+		{
+			Start: time.Date(2022, 2, 6, 22, 0, 0, 0, jktTime),
+			End:   time.Date(2022, 2, 7, 1, 0, 0, 0, jktTime),
+		},
+		{
+			Start: time.Date(2022, 2, 7, 2, 0, 0, 0, time.UTC),
+			End:   time.Date(2022, 2, 7, 3, 30, 0, 0, time.UTC),
+		},
+		{
+			Start: time.Date(2022, 2, 7, 23, 0, 0, 0, jktTime),
+			End:   time.Date(2022, 2, 8, 2, 0, 0, 0, jktTime),
+		},
+	}
+
+	assert.Equal(t, RangeSet{
+		{StartSec: 0, EndSec: 3600},
+		{StartSec: 9 * 3600, EndSec: 10*3600 + 1800},
+		{StartSec: 23 * 3600, EndSec: 24 * 3600},
+	}, is.RangesOn(time.Date(2022, 2, 7, 12, 0, 0, 0, jktTime)))
+}
@@ -0,0 +1,443 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// WeekdayNum is a BYDAY entry. N is the ordinal of the weekday within the
+// month (e.g. 1 for the first, -1 for the last). Zero means every occurrence
+// of the weekday
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Weekday]
+	}
+	return fmt.Sprintf("%d%s", w.N, weekdayNames[w.Weekday])
+}
+
+// Recurrence is a subset of RFC 5545 RRULE. Weeks always start on Monday
+type Recurrence struct {
+	Freq Frequency
+
+	// Interval is the number of periods between occurrences. Zero is
+	// treated as 1
+	Interval int
+
+	// Count limits the number of occurrences. Zero means unlimited
+	Count int
+
+	// Until is the last instant an occurrence may start. Zero means unlimited
+	Until time.Time
+
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+
+	// BySetPos selects occurrences by their position within each period,
+	// e.g. -1 for the last weekday of the month
+	BySetPos []int
+}
+
+// ParseRecurrence parses the value of a RRULE property, e.g.
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=8"
+func ParseRecurrence(rule string) (Recurrence, error) {
+	var r Recurrence
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return Recurrence{}, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), kv[1]
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq, err = parseFrequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(value)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value)
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			r.BySetPos, err = parseInts(value)
+		case "WKST":
+			// weeks always start on monday
+		default:
+			return Recurrence{}, fmt.Errorf("unsupported recurrence part %q", key)
+		}
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("invalid recurrence part %q: %w", part, err)
+		}
+	}
+
+	if err := r.IsValid(); err != nil {
+		return Recurrence{}, err
+	}
+	return r, nil
+}
+
+func parseFrequency(s string) (Frequency, error) {
+	for f, name := range frequencyNames {
+		if strings.EqualFold(name, s) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported frequency %s", s)
+}
+
+func parseUntil(s string) (time.Time, error) {
+	if len(s) == 8 {
+		return time.Parse("20060102", s)
+	}
+	return time.Parse("20060102T150405Z", s)
+}
+
+func parseByDay(s string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, d := range strings.Split(s, ",") {
+		d = strings.ToUpper(strings.TrimSpace(d))
+		if len(d) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", d)
+		}
+		var w WeekdayNum
+		found := false
+		for wd, name := range weekdayNames {
+			if strings.HasSuffix(d, name) {
+				w.Weekday = wd
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid weekday %q", d)
+		}
+		if n := d[:len(d)-2]; n != "" {
+			var err error
+			if w.N, err = strconv.Atoi(n); err != nil {
+				return nil, fmt.Errorf("invalid weekday %q", d)
+			}
+		}
+		days = append(days, w)
+	}
+	return days, nil
+}
+
+func parseInts(s string) ([]int, error) {
+	var ints []int
+	for _, v := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+func (r Recurrence) IsValid() error {
+	if _, ok := frequencyNames[r.Freq]; !ok {
+		return fmt.Errorf("recurrence frequency is required")
+	}
+	if r.Interval < 0 || r.Count < 0 {
+		return fmt.Errorf("recurrence interval and count must not be negative")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("recurrence must not have both count and until")
+	}
+	for _, p := range r.BySetPos {
+		if p == 0 {
+			return fmt.Errorf("recurrence set position must not be zero")
+		}
+	}
+	return nil
+}
+
+// String returns the RRULE value of the recurrence
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, d := range r.ByDay {
+			days = append(days, d.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		var months []string
+		for _, m := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		var positions []string
+		for _, p := range r.BySetPos {
+			positions = append(positions, strconv.Itoa(p))
+		}
+		parts = append(parts, "BYSETPOS="+strings.Join(positions, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns all occurrences of the recurrence starting at dtstart whose
+// start time is within [from, to). The wall clock time of dtstart is kept in
+// its location for every occurrence
+func (r Recurrence) Between(dtstart, from, to time.Time) []time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var occurrences []time.Time
+	count := 0
+	for period := 0; ; period += interval {
+		periodStart := r.periodStart(dtstart, period)
+		if !periodStart.Before(to) {
+			break
+		}
+		if !r.Until.IsZero() && periodStart.After(r.Until) {
+			break
+		}
+
+		for _, o := range r.candidates(dtstart, periodStart) {
+			if o.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && o.After(r.Until) {
+				return occurrences
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return occurrences
+			}
+			if !o.Before(from) && o.Before(to) {
+				occurrences = append(occurrences, o)
+			}
+		}
+	}
+	return occurrences
+}
+
+// periodStart returns midnight of the first day of the n-th period after dtstart
+func (r Recurrence) periodStart(dtstart time.Time, n int) time.Time {
+	y, m, d := dtstart.Date()
+	loc := dtstart.Location()
+	switch r.Freq {
+	case Weekly:
+		monday := d - (int(dtstart.Weekday())+6)%7
+		return time.Date(y, m, monday+7*n, 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, loc)
+	case Yearly:
+		return time.Date(y+n, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d+n, 0, 0, 0, 0, loc)
+	}
+}
+
+// candidates returns the sorted occurrences within the period
+func (r Recurrence) candidates(dtstart, periodStart time.Time) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		days = []time.Time{periodStart}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := periodStart.AddDate(0, 0, i)
+			if len(r.ByDay) > 0 || day.Weekday() == dtstart.Weekday() {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		days = r.monthDays(dtstart, periodStart)
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+		if len(months) == 0 {
+			for m := time.January; m <= time.December; m++ {
+				months = append(months, m)
+			}
+		}
+		for _, m := range months {
+			monthStart := time.Date(periodStart.Year(), m, 1, 0, 0, 0, 0, periodStart.Location())
+			days = append(days, r.monthDays(dtstart, monthStart)...)
+		}
+	}
+
+	var occurrences []time.Time
+	for _, day := range days {
+		if !r.matches(day) {
+			continue
+		}
+		occurrences = append(occurrences, time.Date(day.Year(), day.Month(), day.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location()))
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Before(occurrences[j])
+	})
+	if len(r.BySetPos) > 0 {
+		return r.setPositions(occurrences)
+	}
+	return occurrences
+}
+
+// setPositions returns the sorted occurrences at BYSETPOS positions
+func (r Recurrence) setPositions(occurrences []time.Time) []time.Time {
+	var selected []time.Time
+	for i, o := range occurrences {
+		for _, p := range r.BySetPos {
+			if p == i+1 || p == i-len(occurrences) {
+				selected = append(selected, o)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// monthDays returns all days of the month starting at monthStart. If the
+// recurrence has no day rule, only the day of month of dtstart is returned
+func (r Recurrence) monthDays(dtstart, monthStart time.Time) []time.Time {
+	var days []time.Time
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		day := monthStart.AddDate(0, 0, dtstart.Day()-1)
+		if day.Month() == monthStart.Month() {
+			days = append(days, day)
+		}
+		return days
+	}
+	for day := monthStart; day.Month() == monthStart.Month(); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// matches checks the day against the BYxxx rules
+func (r Recurrence) matches(day time.Time) bool {
+	if len(r.ByMonth) > 0 {
+		found := false
+		for _, m := range r.ByMonth {
+			if day.Month() == m {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		found := false
+		for _, md := range r.ByMonthDay {
+			if md == day.Day() || md < 0 && lastDay+md+1 == day.Day() {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.ByDay) > 0 {
+		found := false
+		for _, wd := range r.ByDay {
+			if wd.Weekday != day.Weekday() {
+				continue
+			}
+			if wd.N == 0 || r.Freq == Daily || r.Freq == Weekly {
+				found = true
+				continue
+			}
+			if wd.N == weekdayOrdinal(day) || wd.N == -weekdayOrdinalFromEnd(day) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// weekdayOrdinal returns n where the day is the n-th of its weekday in the month
+func weekdayOrdinal(day time.Time) int {
+	return (day.Day()-1)/7 + 1
+}
+
+// weekdayOrdinalFromEnd returns n where the day is the n-th last of its
+// weekday in the month
+func weekdayOrdinalFromEnd(day time.Time) int {
+	lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	return (lastDay-day.Day())/7 + 1
+}
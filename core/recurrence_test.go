@@ -0,0 +1,200 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    Recurrence
+		wantErr bool
+	}{
+		{
+			name: "weekly with interval and count",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=8",
+			want: Recurrence{
+				Freq:     Weekly,
+				Interval: 2,
+				Count:    8,
+				ByDay:    []WeekdayNum{{Weekday: time.Tuesday}, {Weekday: time.Thursday}},
+			},
+		},
+		{
+			name: "monthly on the last friday until a date",
+			rule: "RRULE:FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20221231T000000Z",
+			want: Recurrence{
+				Freq:  Monthly,
+				Until: time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+				ByDay: []WeekdayNum{{N: -1, Weekday: time.Friday}},
+			},
+		},
+		{
+			name:    "frequency is required",
+			rule:    "COUNT=2",
+			wantErr: true,
+		},
+		{
+			name:    "count and until are mutually exclusive",
+			rule:    "FREQ=DAILY;COUNT=2;UNTIL=20221231",
+			wantErr: true,
+		},
+		{
+			name:    "unknown weekday",
+			rule:    "FREQ=WEEKLY;BYDAY=XX",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecurrence(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRecurrence() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRecurrence_String(t *testing.T) {
+	rule := "FREQ=MONTHLY;INTERVAL=3;COUNT=4;BYDAY=1MO,-1FR;BYMONTHDAY=1,-1;BYMONTH=1,6;BYSETPOS=1,-1"
+	r, err := ParseRecurrence(rule)
+	assert.NoError(t, err)
+	assert.Equal(t, rule, r.String())
+}
+
+func TestRecurrence_Between(t *testing.T) {
+
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name:    "every tuesday at 10:00 for 3 weeks",
+			rule:    "FREQ=WEEKLY;COUNT=3",
+			dtstart: time.Date(2022, 2, 1, 10, 0, 0, 0, jktTime),
+			from:    time.Date(2022, 1, 1, 0, 0, 0, 0, jktTime),
+			to:      time.Date(2023, 1, 1, 0, 0, 0, 0, jktTime),
+			want: []time.Time{
+				time.Date(2022, 2, 1, 10, 0, 0, 0, jktTime),
+				time.Date(2022, 2, 8, 10, 0, 0, 0, jktTime),
+				time.Date(2022, 2, 15, 10, 0, 0, 0, jktTime),
+			},
+		},
+		{
+			name:    "every other week on monday and friday, count includes occurrences before the window",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=4",
+			dtstart: time.Date(2022, 2, 2, 9, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 2, 5, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 2, 14, 9, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 18, 9, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 28, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "first monday and last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=1MO,-1FR",
+			dtstart: time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 1, 3, 8, 0, 0, 0, time.UTC),
+				time.Date(2022, 1, 28, 8, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 7, 8, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 25, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "monthly skips months without the day",
+			rule:    "FREQ=MONTHLY;COUNT=2",
+			dtstart: time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "daily until",
+			rule:    "FREQ=DAILY;UNTIL=20220203T090000Z",
+			dtstart: time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 3, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "last weekday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 4, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 5, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 7, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "first and second to last day of the week",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR;BYSETPOS=1,-2;COUNT=3",
+			dtstart: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, 2, 14, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "yearly",
+			rule:    "FREQ=YEARLY;COUNT=2",
+			dtstart: time.Date(2022, 8, 17, 0, 0, 0, 0, jktTime),
+			from:    time.Date(2022, 1, 1, 0, 0, 0, 0, jktTime),
+			to:      time.Date(2030, 1, 1, 0, 0, 0, 0, jktTime),
+			want: []time.Time{
+				time.Date(2022, 8, 17, 0, 0, 0, 0, jktTime),
+				time.Date(2023, 8, 17, 0, 0, 0, 0, jktTime),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.rule)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, r.Between(tt.dtstart, tt.from, tt.to))
+		})
+	}
+}
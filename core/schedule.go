@@ -0,0 +1,92 @@
+package core
+
+import (
+	"time"
+)
+
+// Schedule defines the weekly working hours of someone in their own timezone
+type Schedule struct {
+	// Location defines the timezone of the ranges
+	Location *time.Location
+
+	// Availability stores the information about availability for
+	// each day
+	Availability map[time.Weekday][]Range
+
+	// DateOverrides specify the overriding range for a specific day
+	// key is unix timestamp of the 00:00:00 for the given day
+	DateOverrides map[int64][]Range
+
+	// Holidays are the holiday calendars the schedule is subscribed to.
+	// Holidays are unavailable unless overridden by DateOverrides
+	Holidays []*HolidayCalendar
+
+	// Exceptions change the weekly availability of the days matching
+	// their rules. They are applied in order
+	Exceptions []AvailabilityException
+
+	// Travels change the timezone of the working hours on their dates
+	Travels []Travel
+}
+
+// RangesOn returns the available ranges of the given day. Date overrides
+// take precedence over holidays, which take precedence over exceptions to
+// the weekly availability
+func (s Schedule) RangesOn(day time.Time) []Range {
+	key := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, s.Location).Unix()
+	if dateOverrides, ok := s.DateOverrides[key]; ok {
+		return dateOverrides
+	}
+	if s.IsHoliday(day) {
+		return nil
+	}
+	ranges := s.Availability[day.Weekday()]
+	for _, ex := range s.Exceptions {
+		if ex.Matches(day) {
+			ranges = ex.Apply(ranges)
+		}
+	}
+	return ranges
+}
+
+// IsHoliday returns true if the day is a holiday in any of the subscribed
+// calendars
+func (s Schedule) IsHoliday(day time.Time) bool {
+	for _, c := range s.Holidays {
+		if _, ok := c.HolidayOn(day); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableIntervals returns the time within [start, end) covered by the
+// schedule. Ranges of each date are in the timezone of that date
+func (s Schedule) AvailableIntervals(start, end time.Time) Intervals {
+	window := Interval{Start: start, End: end}
+
+	// a travel timezone ahead of the schedule's may start the next date
+	// before the window does
+	st := start.In(s.Location)
+	curr := time.Date(st.Year(), st.Month(), st.Day()-1, 0, 0, 0, 0, s.Location)
+
+	var available Intervals
+	for curr.Before(end) {
+		day := time.Date(curr.Year(), curr.Month(), curr.Day(), 0, 0, 0, 0, s.LocationOn(curr))
+		for _, r := range s.RangesOn(day) {
+			available = append(available, r.Interval(day))
+		}
+		curr = curr.AddDate(0, 0, 1)
+	}
+	return available.Clip(window).Merge()
+}
+
+// RangesOnDate returns the schedule's availability on the given date of
+// another location, e.g. the event's
+func (s Schedule) RangesOnDate(date time.Time) RangeSet {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	if len(s.Travels) == 0 && dayStart.Location() == s.Location {
+		return NewRangeSet(s.RangesOn(dayStart)...)
+	}
+	return s.AvailableIntervals(dayStart, dayStart.AddDate(0, 0, 1)).RangesOn(dayStart)
+}
@@ -0,0 +1,254 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnboundedSeries = fmt.Errorf("series recurrence must have count or until")
+	ErrSeriesNotFound  = fmt.Errorf("series not found")
+)
+
+// SeriesMode defines what happens when some occurrences of a series are not
+// available
+type SeriesMode int
+
+const (
+	// AllOrNothing rejects the whole series if any occurrence is not available
+	AllOrNothing SeriesMode = iota
+	// SkipConflicts books the available occurrences only
+	SkipConflicts
+)
+
+type CreateSeriesParameters struct {
+	Invitee         Invitee
+	Answers         Answers
+	MeetingLocation MeetingLocation
+
+	// StartTime is the first occurrence of the series
+	StartTime time.Time
+
+	// Recurrence defines the following occurrences, e.g. every tuesday for
+	// 8 weeks. It must be bounded by count or until
+	Recurrence Recurrence
+
+	Mode SeriesMode
+}
+
+// BookingSeries is the result of booking a recurring series
+type BookingSeries struct {
+	ID         uuid.UUID
+	Recurrence Recurrence
+
+	// Bookings stores the booked occurrences
+	Bookings Bookings
+
+	// Skipped stores the occurrences which were not available
+	Skipped []time.Time
+}
+
+// CreateSeries books every occurrence of the recurrence. All occurrences are
+// validated before any of them is stored
+func (e *Event) CreateSeries(params CreateSeriesParameters) (*BookingSeries, error) {
+	if err := params.Recurrence.IsValid(); err != nil {
+		return nil, err
+	}
+	if params.Recurrence.Count == 0 && params.Recurrence.Until.IsZero() {
+		return nil, ErrUnboundedSeries
+	}
+
+	series := &BookingSeries{
+		ID:         uuid.New(),
+		Recurrence: params.Recurrence,
+	}
+
+	// occurrences are checked one by one against a tentative copy of the
+	// bookings, so that each of them counts towards the caps and seats of
+	// the following ones. Nothing is stored before every check passed
+	tentative := *e
+	tentative.Bookings = append(Bookings(nil), e.Bookings...)
+	farFuture := params.StartTime.AddDate(100, 0, 0)
+	for _, o := range params.Recurrence.Between(params.StartTime, params.StartTime, farFuture) {
+		b, err := tentative.prepareBooking(CreateBookingParameters{
+			Invitee:         params.Invitee,
+			StartTime:       o,
+			Answers:         params.Answers,
+			MeetingLocation: params.MeetingLocation,
+		})
+		if err != nil {
+			if params.Mode == SkipConflicts && err == ErrTimeNotAvailable {
+				series.Skipped = append(series.Skipped, o)
+				continue
+			}
+			return nil, fmt.Errorf("occurrence %s: %w", o.Format(time.RFC3339), err)
+		}
+		b.SeriesID = series.ID
+		tentative.Bookings = append(tentative.Bookings, *b)
+		series.Bookings = append(series.Bookings, *b)
+	}
+	if len(series.Bookings) == 0 {
+		return nil, ErrTimeNotAvailable
+	}
+
+	for i := range series.Bookings {
+		if err := e.createConference(&series.Bookings[i]); err != nil {
+			for _, b := range series.Bookings[:i] {
+				e.discardConference(b)
+			}
+			return nil, err
+		}
+	}
+	var changes []BookingChange
+	for _, b := range series.Bookings {
+		changes = append(changes, BookingChange{Kind: ChangeCreated, Booking: b})
+	}
+	if err := e.journal(changes...); err != nil {
+		for _, b := range series.Bookings {
+			e.discardConference(b)
+		}
+		return nil, err
+	}
+	e.Bookings = append(e.Bookings, series.Bookings...)
+	for _, b := range series.Bookings {
+		e.notifyChange(ChangeCreated, b, Booking{})
+	}
+	return series, nil
+}
+
+// SeriesBookings returns active bookings of the series
+func (e Event) SeriesBookings(seriesID uuid.UUID) Bookings {
+	var bookings Bookings
+	for _, b := range e.Bookings.Active() {
+		if b.SeriesID == seriesID {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings
+}
+
+// CancelSeries cancels every active occurrence of the series starting at or
+// after from. A single occurrence is cancelled with CancelBooking
+func (e *Event) CancelSeries(seriesID uuid.UUID, from time.Time) (Bookings, error) {
+	bookings := e.SeriesBookings(seriesID)
+	if len(bookings) == 0 {
+		return nil, ErrSeriesNotFound
+	}
+
+	var cancelled Bookings
+	for _, b := range bookings {
+		if b.StartTime.Before(from) {
+			continue
+		}
+		c, err := e.CancelBooking(b.ID)
+		if err != nil {
+			return cancelled, err
+		}
+		cancelled = append(cancelled, *c)
+	}
+	return cancelled, nil
+}
+
+// RescheduleSeries moves every active occurrence starting at or after from.
+// The first of them is moved to startTime and the others keep the same
+// distance in days from it, at the time of day of startTime. Either all
+// occurrences are moved or none. A single occurrence is moved with
+// RescheduleBooking
+func (e *Event) RescheduleSeries(seriesID uuid.UUID, from time.Time, startTime time.Time) (Bookings, error) {
+	var moving Bookings
+	var rest Bookings
+	for _, b := range e.Bookings {
+		if b.SeriesID == seriesID && b.IsActive() && !b.StartTime.Before(from) {
+			moving = append(moving, b)
+		} else {
+			rest = append(rest, b)
+		}
+	}
+	if len(moving) == 0 {
+		return nil, ErrSeriesNotFound
+	}
+
+	first := moving[0].StartTime
+	for _, b := range moving {
+		if b.StartTime.Before(first) {
+			first = b.StartTime
+		}
+	}
+	target := startTime.In(e.Location)
+	dayDelta := daysBetween(first.In(e.Location), target)
+
+	// moved occurrences are taken out first, so that they do not block
+	// each other
+	snapshot := e.Bookings
+	e.Bookings = rest
+
+	var moved Bookings
+	for _, b := range moving {
+		previous := b.StartTime.In(e.Location)
+		newStart := time.Date(previous.Year(), previous.Month(), previous.Day()+dayDelta,
+			target.Hour(), target.Minute(), target.Second(), 0, e.Location)
+
+		if !e.isSpotAvailable(newStart) {
+			e.Bookings = snapshot
+			return nil, fmt.Errorf("occurrence %s: %w", newStart.Format(time.RFC3339), ErrTimeNotAvailable)
+		}
+		if e.Kind == RoundRobin {
+			hosts := e.freeHosts(newStart)
+			if !containsHost(hosts, b.HostID) {
+				b.HostID = e.assignHost(hosts, newStart).ID
+			}
+		}
+		b.StartTime = newStart
+		b.Sequence++
+		if err := e.updateConference(&b); err != nil {
+			e.Bookings = snapshot
+			return nil, err
+		}
+		e.Bookings = append(e.Bookings, b)
+		moved = append(moved, b)
+	}
+
+	var changes []BookingChange
+	for i, b := range moving {
+		changes = append(changes, BookingChange{Kind: ChangeRescheduled, Booking: moved[i], Previous: b})
+	}
+	if err := e.journal(changes...); err != nil {
+		e.Bookings = snapshot
+		for _, b := range moving {
+			e.restoreConference(b)
+		}
+		return nil, err
+	}
+
+	for i, b := range moving {
+		e.notifyChange(ChangeRescheduled, moved[i], b)
+		e.promoteWaitlist(b.StartTime, e.Now())
+	}
+	return moved, nil
+}
+
+// isSpotAvailable returns true if a booking can be created at the given time
+func (e Event) isSpotAvailable(t time.Time) bool {
+	availableSpots, err := e.GetAvailableSpots(GetSpotParameters{
+		Start: t,
+		End:   t.Add(e.Duration),
+	})
+	if err != nil {
+		return false
+	}
+	for _, spot := range availableSpots {
+		if spot.StartTime.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
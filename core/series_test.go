@@ -0,0 +1,282 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func newWeeklyEvent() *Event {
+	return &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 12 * 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+	}
+}
+
+func startTimes(bs Bookings) []time.Time {
+	var times []time.Time
+	for _, b := range bs {
+		times = append(times, b.StartTime)
+	}
+	return times
+}
+
+func TestEvent_CreateSeries(t *testing.T) {
+	monday := func(week, h int) time.Time {
+		return time.Date(2022, 2, 7+7*week, h, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name        string
+		bookings    Bookings
+		recurrence  Recurrence
+		mode        SeriesMode
+		wantErr     error
+		wantTimes   []time.Time
+		wantSkipped []time.Time
+	}{
+		{
+			name:       "should book every occurrence",
+			recurrence: Recurrence{Freq: Weekly, Count: 3},
+			wantTimes:  []time.Time{monday(0, 9), monday(1, 9), monday(2, 9)},
+		},
+		{
+			name:       "should reject unbounded recurrence",
+			recurrence: Recurrence{Freq: Weekly},
+			wantErr:    ErrUnboundedSeries,
+		},
+		{
+			name:       "should reject the whole series if an occurrence is taken",
+			bookings:   Bookings{{ID: uuid.New(), StartTime: monday(1, 9)}},
+			recurrence: Recurrence{Freq: Weekly, Count: 3},
+			wantErr:    ErrTimeNotAvailable,
+		},
+		{
+			name:        "should skip taken occurrences",
+			bookings:    Bookings{{ID: uuid.New(), StartTime: monday(1, 9)}},
+			recurrence:  Recurrence{Freq: Weekly, Count: 3},
+			mode:        SkipConflicts,
+			wantTimes:   []time.Time{monday(0, 9), monday(2, 9)},
+			wantSkipped: []time.Time{monday(1, 9)},
+		},
+		{
+			name:        "should skip occurrences outside availability",
+			recurrence:  Recurrence{Freq: Daily, Until: monday(1, 9)},
+			mode:        SkipConflicts,
+			wantTimes:   []time.Time{monday(0, 9), monday(1, 9)},
+			wantSkipped: []time.Time{time.Date(2022, 2, 8, 9, 0, 0, 0, time.UTC), time.Date(2022, 2, 9, 9, 0, 0, 0, time.UTC), time.Date(2022, 2, 10, 9, 0, 0, 0, time.UTC), time.Date(2022, 2, 11, 9, 0, 0, 0, time.UTC), time.Date(2022, 2, 12, 9, 0, 0, 0, time.UTC), time.Date(2022, 2, 13, 9, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newWeeklyEvent()
+			e.Bookings = tt.bookings
+			got, err := e.CreateSeries(CreateSeriesParameters{
+				Invitee:    Invitee{Email: "foo@bar.com"},
+				StartTime:  monday(0, 9),
+				Recurrence: tt.recurrence,
+				Mode:       tt.mode,
+			})
+			assert.True(t, errors.Is(err, tt.wantErr), "CreateSeries() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(t, got)
+				assert.Equal(t, tt.bookings, e.Bookings)
+				return
+			}
+			assert.Equal(t, tt.wantTimes, startTimes(got.Bookings))
+			assert.Equal(t, tt.wantSkipped, got.Skipped)
+			assert.Equal(t, tt.wantTimes, startTimes(e.SeriesBookings(got.ID)))
+		})
+	}
+}
+
+func TestEvent_CreateSeries_WeeklyCap(t *testing.T) {
+	e := newWeeklyEvent()
+	e.Availability[time.Tuesday] = []Range{{StartSec: 9 * 3600, EndSec: 12 * 3600}}
+	e.WeeklyCap = 1
+
+	got, err := e.CreateSeries(CreateSeriesParameters{
+		Invitee:    Invitee{Email: "foo@bar.com"},
+		StartTime:  time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		Recurrence: Recurrence{Freq: Daily, Count: 2},
+		Mode:       SkipConflicts,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, got.Bookings, 1)
+	assert.Equal(t, []time.Time{time.Date(2022, 2, 8, 9, 0, 0, 0, time.UTC)}, got.Skipped)
+}
+
+func TestEvent_CreateSeries_HostWeeklyCap(t *testing.T) {
+	newEvent := func() *Event {
+		e := newWeeklyEvent()
+		for d := time.Tuesday; d <= time.Friday; d++ {
+			e.Availability[d] = e.Availability[time.Monday]
+		}
+		h := NewHost("Foo Bar", "foo@bar.com")
+		h.WeeklyCap = 2
+		h.AddEvent(e)
+		return e
+	}
+	params := CreateSeriesParameters{
+		Invitee:    Invitee{Email: "foo@bar.com"},
+		StartTime:  time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		Recurrence: Recurrence{Freq: Daily, Count: 5},
+	}
+
+	e := newEvent()
+	_, err := e.CreateSeries(params)
+	assert.True(t, errors.Is(err, ErrTimeNotAvailable))
+	assert.Empty(t, e.Bookings)
+
+	e = newEvent()
+	params.Mode = SkipConflicts
+	got, err := e.CreateSeries(params)
+	assert.NoError(t, err)
+	assert.Len(t, got.Bookings, 2)
+	assert.Len(t, got.Skipped, 3)
+}
+
+func TestEvent_CreateSeries_Rejected(t *testing.T) {
+	var changes []BookingChange
+	publisher := &recordingPublisher{}
+	conferencing := &stubConferenceProvider{}
+	e := newWeeklyEvent()
+	e.OnBookingChange = func(e *Event, c BookingChange) { changes = append(changes, c) }
+	e.Publisher = publisher
+	e.Conferencing = conferencing
+	taken := Booking{ID: uuid.New(), StartTime: time.Date(2022, 2, 21, 9, 0, 0, 0, time.UTC)}
+	e.Bookings = Bookings{taken}
+
+	_, err := e.CreateSeries(CreateSeriesParameters{
+		Invitee:    Invitee{Email: "foo@bar.com"},
+		StartTime:  time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		Recurrence: Recurrence{Freq: Weekly, Count: 4},
+	})
+	assert.True(t, errors.Is(err, ErrTimeNotAvailable))
+	assert.Equal(t, Bookings{taken}, e.Bookings)
+	assert.Empty(t, changes)
+	assert.Empty(t, publisher.published)
+	assert.Zero(t, conferencing.created)
+
+	series, err := e.CreateSeries(CreateSeriesParameters{
+		Invitee:    Invitee{Email: "foo@bar.com"},
+		StartTime:  time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		Recurrence: Recurrence{Freq: Weekly, Count: 4},
+		Mode:       SkipConflicts,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+	assert.Len(t, publisher.published, 3)
+	assert.Equal(t, 3, conferencing.created)
+	for i, c := range changes {
+		assert.Equal(t, series.ID, c.Booking.SeriesID)
+		assert.Equal(t, series.Bookings[i], c.Booking)
+	}
+}
+
+func TestEvent_CancelSeries(t *testing.T) {
+	e := newWeeklyEvent()
+	series, err := e.CreateSeries(CreateSeriesParameters{
+		Invitee:    Invitee{Email: "foo@bar.com"},
+		StartTime:  time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		Recurrence: Recurrence{Freq: Weekly, Count: 4},
+	})
+	assert.NoError(t, err)
+
+	// a single occurrence
+	_, err = e.CancelBooking(series.Bookings[0].ID)
+	assert.NoError(t, err)
+
+	// this and following occurrences
+	cancelled, err := e.CancelSeries(series.ID, time.Date(2022, 2, 21, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2022, 2, 21, 9, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 28, 9, 0, 0, 0, time.UTC),
+	}, startTimes(cancelled))
+	for _, b := range cancelled {
+		assert.Equal(t, BookingCancelled, b.Status)
+	}
+	assert.Equal(t, []time.Time{time.Date(2022, 2, 14, 9, 0, 0, 0, time.UTC)}, startTimes(e.SeriesBookings(series.ID)))
+
+	_, err = e.CancelSeries(uuid.New(), time.Time{})
+	assert.Equal(t, ErrSeriesNotFound, err)
+}
+
+func TestEvent_RescheduleSeries(t *testing.T) {
+	monday := func(week, h int) time.Time {
+		return time.Date(2022, 2, 7+7*week, h, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name      string
+		from      time.Time
+		startTime time.Time
+		other     *Booking
+		wantErr   error
+		wantTimes []time.Time
+	}{
+		{
+			name:      "should move the whole series to another hour",
+			startTime: monday(0, 10),
+			wantTimes: []time.Time{monday(0, 10), monday(1, 10), monday(2, 10)},
+		},
+		{
+			name:      "should move following occurrences only",
+			from:      monday(1, 0),
+			startTime: monday(1, 11),
+			wantTimes: []time.Time{monday(0, 9), monday(1, 11), monday(2, 11)},
+		},
+		{
+			name:      "should not move to a time which is not a slot",
+			startTime: time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC),
+			wantErr:   ErrTimeNotAvailable,
+			wantTimes: []time.Time{monday(0, 9), monday(1, 9), monday(2, 9)},
+		},
+		{
+			name:      "should not move any occurrence if one of them is taken",
+			startTime: monday(0, 10),
+			other:     &Booking{ID: uuid.New(), StartTime: monday(2, 10)},
+			wantErr:   ErrTimeNotAvailable,
+			wantTimes: []time.Time{monday(0, 9), monday(1, 9), monday(2, 9)},
+		},
+		{
+			name:      "should not move to a day outside availability",
+			startTime: time.Date(2022, 2, 8, 9, 0, 0, 0, time.UTC),
+			wantErr:   ErrTimeNotAvailable,
+			wantTimes: []time.Time{monday(0, 9), monday(1, 9), monday(2, 9)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newWeeklyEvent()
+			series, err := e.CreateSeries(CreateSeriesParameters{
+				Invitee:    Invitee{Email: "foo@bar.com"},
+				StartTime:  monday(0, 9),
+				Recurrence: Recurrence{Freq: Weekly, Count: 3},
+			})
+			assert.NoError(t, err)
+			if tt.other != nil {
+				e.Bookings = append(e.Bookings, *tt.other)
+			}
+
+			moved, err := e.RescheduleSeries(series.ID, tt.from, tt.startTime)
+			assert.True(t, errors.Is(err, tt.wantErr), "RescheduleSeries() error = %v, wantErr %v", err, tt.wantErr)
+			assert.ElementsMatch(t, tt.wantTimes, startTimes(e.SeriesBookings(series.ID)))
+			if tt.wantErr != nil {
+				assert.Nil(t, moved)
+				return
+			}
+			for _, b := range moved {
+				assert.Equal(t, 1, b.Sequence)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventKind defines how an event is hosted
+type EventKind int
+
+const (
+	// SingleHost event is hosted by its owner
+	SingleHost EventKind = iota
+	// RoundRobin event is hosted by one of its team members. A spot is
+	// available if at least one member is free
+	RoundRobin
+	// Collective event is hosted by all of its team members together. A
+	// spot is available only if every member is free
+	Collective
+)
+
+// AssignmentStrategy defines how a round robin booking picks a host among
+// the free team members
+type AssignmentStrategy int
+
+const (
+	// LeastRecentlyBooked picks the member whose last booking was created
+	// the earliest. Members who have never been booked come first
+	LeastRecentlyBooked AssignmentStrategy = iota
+	// FewestBookingsThisWeek picks the member with the least bookings in
+	// the week (starting on monday) of the booked spot
+	FewestBookingsThisWeek
+	// WeightedPriority distributes bookings in proportion to the members'
+	// weight by picking the one with the lowest bookings to weight ratio
+	WeightedPriority
+)
+
+// AddTeamMember adds the host to the hosts pool of the event
+func (e *Event) AddTeamMember(h *Host) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	e.Hosts = append(e.Hosts, h)
+	h.Events = append(h.Events, e)
+}
+
+// BookingHost returns the host organizing the booking, i.e. the assigned host
+// of a round robin booking or the event owner. Team events without owner are
+// organized by their first member. It returns nil if the event has no host
+func (e Event) BookingHost(b Booking) *Host {
+	if b.HostID != uuid.Nil {
+		for _, h := range e.Hosts {
+			if h.ID == b.HostID {
+				return h
+			}
+		}
+	}
+	if e.Host == nil && len(e.Hosts) > 0 {
+		return e.Hosts[0]
+	}
+	return e.Host
+}
+
+// BookingsOf returns active bookings which take the host's time. Bookings
+// without assigned host belong to the event owner, and bookings of a
+// collective event belong to every team member
+func (e Event) BookingsOf(hostID uuid.UUID) Bookings {
+	collective := e.Kind == Collective && containsHost(e.Hosts, hostID)
+
+	var bookings Bookings
+	for _, b := range e.Bookings.holding(e.Now()).Active() {
+		if collective || b.HostID == hostID || b.HostID == uuid.Nil && e.Host != nil && e.Host.ID == hostID {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings
+}
+
+// teamBusyIntervals returns the busy time of each team member within
+// [from, to) keyed by host id. Bookings of a collective event are not
+// included since they are limited by MaxInvitees instead
+func (e Event) teamBusyIntervals(from, to time.Time) map[uuid.UUID]Intervals {
+	eventBusy := append(Intervals(nil), e.BusyTimes...)
+	eventBusy = append(eventBusy, busyFrom(e.BusySources, from, to)...)
+
+	busy := map[uuid.UUID]Intervals{}
+	for _, h := range e.Hosts {
+		hostBusy := append(Intervals(nil), eventBusy...)
+		hostBusy = append(hostBusy, h.BusyIntervals(e.ID, from, to)...)
+		if e.Kind == RoundRobin {
+			hostBusy = append(hostBusy, e.BookingsOf(h.ID).Intervals(e.Duration)...)
+		}
+		busy[h.ID] = hostBusy
+	}
+	return busy
+}
+
+// hostRanges returns the event's availability on the day limited by the
+// host's own schedule
+func (e Event) hostRanges(h *Host, day time.Time) RangeSet {
+	ranges := e.Schedule().RangesOnDate(day)
+	if h.Schedule != nil {
+		ranges = ranges.Intersect(h.Schedule.RangesOnDate(day))
+	}
+	return ranges
+}
+
+func (e Event) hostSlots(h *Host, day time.Time, busy Intervals) []time.Time {
+	return e.hostRanges(h, day).Subtract(busy.RangesOn(day)).Slots(day, e.Duration)
+}
+
+// collectiveRanges returns the ranges of the day where every team member is
+// free
+func (e Event) collectiveRanges(day time.Time, busy Intervals, teamBusy map[uuid.UUID]Intervals) RangeSet {
+	free := e.Schedule().RangesOnDate(day).Subtract(busy.RangesOn(day))
+	for _, h := range e.Hosts {
+		free = free.Intersect(e.hostRanges(h, day).Subtract(teamBusy[h.ID].RangesOn(day)))
+	}
+	return free
+}
+
+// roundRobinSpots returns the spots of the day where at least one team
+// member is free. InviteeRemaining is the number of free members
+func (e Event) roundRobinSpots(day time.Time, teamBusy map[uuid.UUID]Intervals) []Spot {
+	remaining := map[int64]int{}
+	var slots []time.Time
+	for _, h := range e.Hosts {
+		for _, slot := range e.hostSlots(h, day, teamBusy[h.ID]) {
+			if h.capReached(slot, e.Location, &e) {
+				continue
+			}
+			if remaining[slot.Unix()] == 0 {
+				slots = append(slots, slot)
+			}
+			remaining[slot.Unix()]++
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Before(slots[j])
+	})
+
+	var spots []Spot
+	for _, slot := range slots {
+		spots = append(spots, Spot{
+			InviteeRemaining: remaining[slot.Unix()],
+			StartTime:        slot,
+		})
+	}
+	return spots
+}
+
+// freeHosts returns the team members who can take a booking at the given time
+func (e Event) freeHosts(startTime time.Time) []*Host {
+	st := startTime.In(e.Location)
+	day := time.Date(st.Year(), st.Month(), st.Day(), 0, 0, 0, 0, e.Location)
+	teamBusy := e.teamBusyIntervals(day, day.AddDate(0, 0, 1))
+
+	var hosts []*Host
+	for _, h := range e.Hosts {
+		for _, slot := range e.hostSlots(h, day, teamBusy[h.ID]) {
+			if slot.Equal(startTime) && !h.capReached(slot, e.Location, &e) {
+				hosts = append(hosts, h)
+				break
+			}
+		}
+	}
+	return hosts
+}
+
+// assignHost picks one of the free hosts according to the event's strategy
+func (e Event) assignHost(hosts []*Host, startTime time.Time) *Host {
+	if len(hosts) == 0 {
+		return nil
+	}
+	best := hosts[0]
+	for _, h := range hosts[1:] {
+		if e.prefers(h, best, startTime) {
+			best = h
+		}
+	}
+	return best
+}
+
+// prefers returns true if a should be assigned rather than b
+func (e Event) prefers(a, b *Host, startTime time.Time) bool {
+	switch e.Assignment {
+	case FewestBookingsThisWeek:
+		countA, countB := e.weekBookingCount(a.ID, startTime), e.weekBookingCount(b.ID, startTime)
+		if countA != countB {
+			return countA < countB
+		}
+	case WeightedPriority:
+		// compare countA/weightA < countB/weightB without division
+		countA, countB := len(e.BookingsOf(a.ID)), len(e.BookingsOf(b.ID))
+		ratioA, ratioB := countA*e.hostWeight(b.ID), countB*e.hostWeight(a.ID)
+		if ratioA != ratioB {
+			return ratioA < ratioB
+		}
+		if e.hostWeight(a.ID) != e.hostWeight(b.ID) {
+			return e.hostWeight(a.ID) > e.hostWeight(b.ID)
+		}
+	}
+	return e.lastBookedAt(a.ID).Before(e.lastBookedAt(b.ID))
+}
+
+func (e Event) lastBookedAt(hostID uuid.UUID) time.Time {
+	var last time.Time
+	for _, b := range e.BookingsOf(hostID) {
+		if b.CreatedAt.After(last) {
+			last = b.CreatedAt
+		}
+	}
+	return last
+}
+
+func (e Event) weekBookingCount(hostID uuid.UUID, t time.Time) int {
+	weekStart := startOfWeek(t.In(e.Location))
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	var count int
+	for _, b := range e.BookingsOf(hostID) {
+		if !b.StartTime.Before(weekStart) && b.StartTime.Before(weekEnd) {
+			count++
+		}
+	}
+	return count
+}
+
+func (e Event) hostWeight(hostID uuid.UUID) int {
+	if w, ok := e.HostWeights[hostID]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+func containsHost(hosts []*Host, id uuid.UUID) bool {
+	for _, h := range hosts {
+		if h.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfWeek returns midnight of the monday of the week of t
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,276 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+// newRoundRobinEvent returns a round robin event available on monday 09:00 -
+// 11:00 UTC. Host b only works 10:00 - 11:00 UTC (17:00 - 18:00 Jakarta) and
+// host c is busy 09:00 - 10:00 UTC on Feb 7, 2022
+func newRoundRobinEvent(t *testing.T) (e *Event, a, b, c *Host) {
+	jktTime, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	e = &Event{
+		Kind:     RoundRobin,
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 11 * 3600}},
+		},
+		Location: time.UTC,
+	}
+
+	a = NewHost("A", "a@foo.com")
+	b = NewHost("B", "b@foo.com")
+	b.Schedule = &Schedule{
+		Location: jktTime,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 17 * 3600, EndSec: 18 * 3600}},
+		},
+	}
+	c = NewHost("C", "c@foo.com")
+	c.BusyTimes = Intervals{{
+		Start: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC),
+	}}
+
+	e.AddTeamMember(a)
+	e.AddTeamMember(b)
+	e.AddTeamMember(c)
+	return e, a, b, c
+}
+
+func TestEvent_GetAvailableSpots_RoundRobin(t *testing.T) {
+	e, a, _, _ := newRoundRobinEvent(t)
+
+	other := &Event{
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 24 * 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+	}
+	a.AddEvent(other)
+
+	params := GetSpotParameters{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	got, err := e.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{
+		{StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC), InviteeRemaining: 1},
+		{StartTime: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC), InviteeRemaining: 3},
+	}, got)
+
+	// host a is the only one free at 09:00
+	_, err = other.CreateBooking(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	got, err = e.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{
+		{StartTime: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC), InviteeRemaining: 3},
+	}, got)
+}
+
+func TestEvent_CreateBooking_RoundRobin(t *testing.T) {
+	slot := time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		strategy AssignmentStrategy
+		setup    func(e *Event, a, b, c *Host)
+		wantHost func(a, b, c *Host) *Host
+	}{
+		{
+			name:     "least recently booked picks host who has never been booked",
+			strategy: LeastRecentlyBooked,
+			setup: func(e *Event, a, b, c *Host) {
+				e.Bookings = Bookings{
+					{ID: uuid.New(), HostID: a.ID, StartTime: time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC), CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: b.ID, StartTime: time.Date(2022, 1, 24, 9, 0, 0, 0, time.UTC), CreatedAt: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)},
+				}
+			},
+			wantHost: func(a, b, c *Host) *Host { return c },
+		},
+		{
+			name:     "least recently booked picks host with the oldest booking",
+			strategy: LeastRecentlyBooked,
+			setup: func(e *Event, a, b, c *Host) {
+				e.Bookings = Bookings{
+					{ID: uuid.New(), HostID: a.ID, StartTime: time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC), CreatedAt: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: b.ID, StartTime: time.Date(2022, 1, 24, 9, 0, 0, 0, time.UTC), CreatedAt: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: c.ID, StartTime: time.Date(2022, 1, 17, 9, 0, 0, 0, time.UTC), CreatedAt: time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+				}
+			},
+			wantHost: func(a, b, c *Host) *Host { return b },
+		},
+		{
+			name:     "fewest bookings this week",
+			strategy: FewestBookingsThisWeek,
+			setup: func(e *Event, a, b, c *Host) {
+				e.Bookings = Bookings{
+					{ID: uuid.New(), HostID: a.ID, StartTime: time.Date(2022, 2, 8, 9, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: b.ID, StartTime: time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: b.ID, StartTime: time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: c.ID, StartTime: time.Date(2022, 2, 13, 9, 0, 0, 0, time.UTC)},
+				}
+			},
+			wantHost: func(a, b, c *Host) *Host { return b },
+		},
+		{
+			name:     "weighted priority picks host with the lowest bookings to weight ratio",
+			strategy: WeightedPriority,
+			setup: func(e *Event, a, b, c *Host) {
+				e.HostWeights = map[uuid.UUID]int{a.ID: 3}
+				e.Bookings = Bookings{
+					{ID: uuid.New(), HostID: a.ID, StartTime: time.Date(2022, 1, 3, 9, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: a.ID, StartTime: time.Date(2022, 1, 10, 9, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: b.ID, StartTime: time.Date(2022, 1, 17, 9, 0, 0, 0, time.UTC)},
+					{ID: uuid.New(), HostID: c.ID, StartTime: time.Date(2022, 1, 24, 9, 0, 0, 0, time.UTC)},
+				}
+			},
+			wantHost: func(a, b, c *Host) *Host { return a },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, a, b, c := newRoundRobinEvent(t)
+			e.Assignment = tt.strategy
+			tt.setup(e, a, b, c)
+
+			got, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHost(a, b, c).ID, got.HostID)
+		})
+	}
+}
+
+func TestEvent_CreateBooking_RoundRobinUntilFull(t *testing.T) {
+	e, a, b, c := newRoundRobinEvent(t)
+	slot := time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC)
+
+	var assigned []uuid.UUID
+	for i := 0; i < 3; i++ {
+		got, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+		assert.NoError(t, err)
+		assigned = append(assigned, got.HostID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{a.ID, b.ID, c.ID}, assigned)
+
+	_, err := e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.True(t, errors.Is(err, ErrTimeNotAvailable))
+}
+
+func TestEvent_RescheduleBooking_RoundRobin(t *testing.T) {
+	e, a, b, _ := newRoundRobinEvent(t)
+	e.Bookings = Bookings{
+		{ID: uuid.New(), HostID: b.ID, StartTime: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC)},
+	}
+
+	got, err := e.RescheduleBooking(e.Bookings[0].ID, time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, a.ID, got.HostID, "booking is reassigned since host b does not work at 09:00")
+}
+
+func TestEvent_Collective(t *testing.T) {
+	jktTime, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+	nyTime, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	panel := &Event{
+		Kind:     Collective,
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 8 * 3600, EndSec: 12 * 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+	}
+
+	// 09:00 - 11:00 UTC
+	a := NewHost("A", "a@foo.com")
+	a.Schedule = &Schedule{
+		Location: jktTime,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 16 * 3600, EndSec: 18 * 3600}},
+		},
+	}
+	// 09:00 - 10:30 UTC
+	b := NewHost("B", "b@foo.com")
+	b.Schedule = &Schedule{
+		Location: nyTime,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 4 * 3600, EndSec: 5*3600 + 1800}},
+		},
+	}
+	c := NewHost("C", "c@foo.com")
+	chat := &Event{
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 8 * 3600, EndSec: 12 * 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Bookings: Bookings{
+			{ID: uuid.New(), StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)},
+		},
+	}
+	c.AddEvent(chat)
+
+	panel.AddTeamMember(a)
+	panel.AddTeamMember(b)
+	panel.AddTeamMember(c)
+
+	params := GetSpotParameters{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	got, err := panel.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{
+		{StartTime: time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC), InviteeRemaining: 1},
+		{StartTime: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC), InviteeRemaining: 1},
+	}, got)
+
+	_, err = panel.CreateBooking(CreateBookingParameters{
+		StartTime: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	got, err = panel.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []Spot{
+		{StartTime: time.Date(2022, 2, 7, 9, 30, 0, 0, time.UTC), InviteeRemaining: 1},
+	}, got)
+
+	// the panel booking blocks every participant's calendar
+	chatSpots, err := chat.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	for _, s := range chatSpots {
+		assert.False(t, s.StartTime.Equal(time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC)))
+	}
+	for _, h := range []*Host{a, b, c} {
+		assert.Len(t, panel.BookingsOf(h.ID), 1)
+	}
+	fb, err := a.GetFreeBusy(FreeBusyParameters{Start: params.Start, End: params.End})
+	assert.NoError(t, err)
+	assert.Equal(t, Intervals{{
+		Start: time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 2, 7, 10, 30, 0, 0, time.UTC),
+	}}, fb.Busy)
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"time"
+)
+
+// Travel is a stay in another timezone. Working hours on the dates from Start
+// to End, both inclusive, follow Location instead of the schedule's
+type Travel struct {
+	Start, End time.Time
+	Location   *time.Location
+}
+
+// Includes returns true if the calendar date of day is within the travel
+func (t Travel) Includes(day time.Time) bool {
+	date := day.Format("2006-01-02")
+	return date >= t.Start.Format("2006-01-02") && date <= t.End.Format("2006-01-02")
+}
+
+// LocationOn returns the timezone of the working hours on the calendar date
+// of day. Later travels take precedence over earlier ones
+func (s Schedule) LocationOn(day time.Time) *time.Location {
+	loc := s.Location
+	for _, t := range s.Travels {
+		if t.Includes(day) {
+			loc = t.Location
+		}
+	}
+	return loc
+}
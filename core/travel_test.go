@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+)
+
+func TestSchedule_AvailableIntervals_Travels(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	workday := []Range{{StartSec: 9 * 3600, EndSec: 17 * 3600}}
+
+	s := Schedule{
+		Location: jakarta,
+		Availability: map[time.Weekday][]Range{
+			time.Monday:    workday,
+			time.Tuesday:   workday,
+			time.Wednesday: workday,
+		},
+		Travels: []Travel{{
+			Start:    time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+			End:      time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC),
+			Location: tokyo,
+		}},
+	}
+
+	got := s.AvailableIntervals(time.Date(2022, 2, 7, 0, 0, 0, 0, jakarta), time.Date(2022, 2, 10, 0, 0, 0, 0, jakarta))
+	want := Intervals{
+		{Start: time.Date(2022, 2, 7, 9, 0, 0, 0, jakarta), End: time.Date(2022, 2, 7, 17, 0, 0, 0, jakarta)},
+		{Start: time.Date(2022, 2, 8, 9, 0, 0, 0, tokyo), End: time.Date(2022, 2, 8, 17, 0, 0, 0, tokyo)},
+		{Start: time.Date(2022, 2, 9, 9, 0, 0, 0, jakarta), End: time.Date(2022, 2, 9, 17, 0, 0, 0, jakarta)},
+	}
+	assert.Len(t, got, len(want))
+	for i := range want {
+		assert.True(t, want[i].Start.Equal(got[i].Start), "start %d = %v, want %v", i, got[i].Start, want[i].Start)
+		assert.True(t, want[i].End.Equal(got[i].End), "end %d = %v, want %v", i, got[i].End, want[i].End)
+	}
+
+	assert.Equal(t, tokyo, s.LocationOn(time.Date(2022, 2, 8, 12, 0, 0, 0, jakarta)))
+	assert.Equal(t, jakarta, s.LocationOn(time.Date(2022, 2, 9, 0, 0, 0, 0, jakarta)))
+}
+
+func TestEvent_GetAvailableSpots_Travels(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	newYork, _ := time.LoadLocation("America/New_York")
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday:  []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+			time.Tuesday: []Range{{StartSec: 9 * 3600, EndSec: 10 * 3600}},
+		},
+		Location: jakarta,
+		Travels: []Travel{{
+			Start:    time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+			End:      time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+			Location: newYork,
+		}},
+		MaxInvitees: 1,
+	}
+
+	got, err := e.GetAvailableSpots(GetSpotParameters{
+		Start: time.Date(2022, 2, 7, 0, 0, 0, 0, jakarta),
+		End:   time.Date(2022, 2, 9, 0, 0, 0, 0, jakarta),
+	})
+	assert.NoError(t, err)
+	// 09:00 in New York on monday is 21:00 in Jakarta
+	assert.Equal(t, []Spot{
+		{InviteeRemaining: 1, StartTime: time.Date(2022, 2, 7, 21, 0, 0, 0, jakarta)},
+		{InviteeRemaining: 1, StartTime: time.Date(2022, 2, 8, 9, 0, 0, 0, jakarta)},
+	}, got)
+}
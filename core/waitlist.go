@@ -0,0 +1,288 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSpotAvailable          = fmt.Errorf("spot is still available")
+	ErrWaitlistEntryNotFound  = fmt.Errorf("waitlist entry not found")
+	ErrWaitlistOfferNotActive = fmt.Errorf("waitlist entry has no active offer")
+)
+
+type WaitlistStatus int
+
+const (
+	// WaitlistWaiting entry is waiting for a seat to be freed
+	WaitlistWaiting WaitlistStatus = iota
+	// WaitlistOffered entry holds a freed seat until the offer expires
+	WaitlistOffered
+	// WaitlistPromoted entry has been booked
+	WaitlistPromoted
+	// WaitlistExpired entry did not claim the offered seat in time
+	WaitlistExpired
+	// WaitlistLeft entry has left the waitlist
+	WaitlistLeft
+)
+
+func (s WaitlistStatus) String() string {
+	switch s {
+	case WaitlistWaiting:
+		return "waiting"
+	case WaitlistOffered:
+		return "offered"
+	case WaitlistPromoted:
+		return "promoted"
+	case WaitlistExpired:
+		return "expired"
+	case WaitlistLeft:
+		return "left"
+	default:
+		return "unknown"
+	}
+}
+
+type WaitlistEntry struct {
+	ID        uuid.UUID
+	Invitee   Invitee
+	StartTime time.Time
+	Answers   Answers
+	JoinedAt  time.Time
+
+	MeetingLocation MeetingLocation
+
+	Status WaitlistStatus
+
+	// OfferExpiresAt is the deadline to claim the offered seat
+	OfferExpiresAt time.Time
+
+	// BookingID is the booking created once the entry is promoted
+	BookingID uuid.UUID
+}
+
+// IsHolding returns true if the entry holds a seat at the given time
+func (w WaitlistEntry) IsHolding(now time.Time) bool {
+	return w.Status == WaitlistOffered && now.Before(w.OfferExpiresAt)
+}
+
+func (w WaitlistEntry) bookingParameters() CreateBookingParameters {
+	return CreateBookingParameters{
+		Invitee:         w.Invitee,
+		StartTime:       w.StartTime,
+		Answers:         w.Answers,
+		MeetingLocation: w.MeetingLocation,
+	}
+}
+
+// WaitlistHook is called whenever a waitlister is offered a seat or booked
+type WaitlistHook func(e *Event, entry WaitlistEntry)
+
+type Waitlist []WaitlistEntry
+
+// HeldCount returns the number of seats held by offers for the given time
+func (w Waitlist) HeldCount(t time.Time, now time.Time) int {
+	var count int
+	for _, entry := range w {
+		if entry.StartTime.Equal(t) && entry.IsHolding(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// heldIntervals returns the time held by offers at now, given the meetings
+// take the duration
+func (w Waitlist) heldIntervals(d time.Duration, now time.Time) Intervals {
+	var held Intervals
+	for _, entry := range w {
+		if entry.IsHolding(now) {
+			held = append(held, Interval{Start: entry.StartTime, End: entry.StartTime.Add(d)})
+		}
+	}
+	return held
+}
+
+// Find returns the index of entry with the given id or -1 if not found
+func (w Waitlist) Find(id uuid.UUID) int {
+	for i, entry := range w {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// next returns the index of the first waiting entry for the given time or -1
+func (w Waitlist) next(t time.Time) int {
+	for i, entry := range w {
+		if entry.StartTime.Equal(t) && entry.Status == WaitlistWaiting {
+			return i
+		}
+	}
+	return -1
+}
+
+// JoinWaitlist puts the invitee in line for a fully booked spot
+func (e *Event) JoinWaitlist(params CreateBookingParameters) (*WaitlistEntry, error) {
+	if e.Paused {
+		return nil, ErrEventNotAcceptingBookings
+	}
+	if !e.IsActiveOn(params.StartTime) {
+		return nil, ErrTimeNotAvailable
+	}
+	answers, err := e.Questions.Validate(params.Answers)
+	if err != nil {
+		return nil, err
+	}
+	meetingLocation, err := e.chooseMeetingLocation(params.MeetingLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	availableSpots, err := e.GetAvailableSpots(GetSpotParameters{
+		Start: params.StartTime,
+		End:   params.StartTime.Add(e.Duration),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, spot := range availableSpots {
+		if spot.StartTime.Equal(params.StartTime) {
+			return nil, ErrSpotAvailable
+		}
+	}
+
+	// only spots which are taken by bookings have a waitlist
+	now := e.Now()
+	taken := e.Bookings.holding(now).GetBookedCount(params.StartTime) + e.Waitlist.HeldCount(params.StartTime, now)
+	if taken == 0 || taken < e.MaxInvitees {
+		return nil, ErrTimeNotAvailable
+	}
+
+	entry := WaitlistEntry{
+		ID:              uuid.New(),
+		Invitee:         params.Invitee,
+		StartTime:       params.StartTime,
+		Answers:         answers,
+		JoinedAt:        now,
+		MeetingLocation: meetingLocation,
+	}
+	e.Waitlist = append(e.Waitlist, entry)
+	return &entry, nil
+}
+
+// LeaveWaitlist removes the invitee from the line. A held seat is offered to
+// the next waitlister
+func (e *Event) LeaveWaitlist(id uuid.UUID) error {
+	i := e.Waitlist.Find(id)
+	if i < 0 {
+		return ErrWaitlistEntryNotFound
+	}
+	offered := e.Waitlist[i].Status == WaitlistOffered
+	e.Waitlist[i].Status = WaitlistLeft
+	if offered {
+		e.promoteWaitlist(e.Waitlist[i].StartTime, e.Now())
+	}
+	return nil
+}
+
+// ClaimWaitlistOffer books the seat offered to the waitlister. The offer
+// expires if the spot can no longer be booked, e.g. the host was booked by
+// another event meanwhile
+func (e *Event) ClaimWaitlistOffer(id uuid.UUID) (*Booking, error) {
+	i := e.Waitlist.Find(id)
+	if i < 0 {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	if e.Paused {
+		return nil, ErrEventNotAcceptingBookings
+	}
+	now := e.Now()
+	if !e.Waitlist[i].IsHolding(now) {
+		e.ExpireWaitlistOffers(now)
+		return nil, ErrWaitlistOfferNotActive
+	}
+	b, err := e.bookWaitlister(i)
+	if err == ErrTimeNotAvailable {
+		e.Waitlist[i].Status = WaitlistExpired
+	}
+	return b, err
+}
+
+// ExpireWaitlistOffers moves offers which were not claimed in time to the
+// next waitlister
+func (e *Event) ExpireWaitlistOffers(now time.Time) {
+	var freed []time.Time
+	for i, entry := range e.Waitlist {
+		if entry.Status == WaitlistOffered && !entry.IsHolding(now) {
+			e.Waitlist[i].Status = WaitlistExpired
+			freed = append(freed, entry.StartTime)
+		}
+	}
+	for _, t := range freed {
+		e.promoteWaitlist(t, now)
+	}
+}
+
+// promoteWaitlist offers the free seats at the given time to the waitlisters
+// in line. Without promotion window the waitlisters are booked right away.
+// Seats are only offered if the spot can still be booked. Otherwise, or if
+// the booking fails, e.g. its conference can not be created, the waitlister
+// stays in line for the next freed seat. Paused events promote nobody
+func (e *Event) promoteWaitlist(t time.Time, now time.Time) {
+	if e.Paused {
+		return
+	}
+	for {
+		free := e.MaxInvitees - e.Bookings.holding(now).GetBookedCount(t) - e.Waitlist.HeldCount(t, now)
+		i := e.Waitlist.next(t)
+		if free <= 0 || i < 0 {
+			return
+		}
+
+		if e.PromotionWindow <= 0 {
+			if _, err := e.bookWaitlister(i); err != nil {
+				return
+			}
+		} else {
+			if _, err := e.prepareBooking(e.Waitlist[i].bookingParameters()); err != nil {
+				return
+			}
+			e.Waitlist[i].Status = WaitlistOffered
+			e.Waitlist[i].OfferExpiresAt = now.Add(e.PromotionWindow)
+		}
+
+		if e.OnWaitlistPromotion != nil {
+			e.OnWaitlistPromotion(e, e.Waitlist[i])
+		}
+		e.publish(WaitlistPromotedEvent{EventID: e.ID, Entry: e.Waitlist[i], At: now, Actor: e.actor})
+	}
+}
+
+// bookWaitlister books the entry if its spot can still be booked. The seat
+// held by the entry's own offer is not counted against it
+func (e *Event) bookWaitlister(i int) (*Booking, error) {
+	tentative := *e
+	tentative.Waitlist = append(Waitlist(nil), e.Waitlist...)
+	tentative.Waitlist[i].Status = WaitlistWaiting
+	b, err := tentative.prepareBooking(e.Waitlist[i].bookingParameters())
+	if err != nil {
+		return nil, err
+	}
+	if err := e.createConference(b); err != nil {
+		return nil, err
+	}
+	if err := e.journal(BookingChange{Kind: ChangeCreated, Booking: *b}); err != nil {
+		e.discardConference(*b)
+		return nil, err
+	}
+	e.Bookings = append(e.Bookings, *b)
+
+	e.Waitlist[i].Status = WaitlistPromoted
+	e.Waitlist[i].BookingID = b.ID
+	e.notifyChange(ChangeCreated, *b, Booking{})
+	return b, nil
+}
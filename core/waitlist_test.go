@@ -0,0 +1,255 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/clocktest"
+)
+
+func newFullyBookedEvent(window time.Duration) (*Event, *[]WaitlistEntry) {
+	var promoted []WaitlistEntry
+	e := &Event{
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]Range{
+			time.Monday: []Range{{StartSec: 0, EndSec: 7200}},
+		},
+		Location: time.UTC,
+		Bookings: Bookings{
+			{ID: uuid.New(), StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)},
+			{ID: uuid.New(), StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)},
+		},
+		MaxInvitees:     2,
+		PromotionWindow: window,
+		OnWaitlistPromotion: func(e *Event, entry WaitlistEntry) {
+			promoted = append(promoted, entry)
+		},
+	}
+	return e, &promoted
+}
+
+func TestEvent_JoinWaitlist(t *testing.T) {
+	tests := []struct {
+		name      string
+		startTime time.Time
+		wantErr   error
+	}{
+		{
+			name:      "should join waitlist of a fully booked spot",
+			startTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+			wantErr:   nil,
+		},
+		{
+			name:      "should not join waitlist if the spot is still available",
+			startTime: time.Date(2022, 2, 7, 1, 0, 0, 0, time.UTC),
+			wantErr:   ErrSpotAvailable,
+		},
+		{
+			name:      "should not join waitlist of time outside availability",
+			startTime: time.Date(2022, 2, 7, 5, 0, 0, 0, time.UTC),
+			wantErr:   ErrTimeNotAvailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newFullyBookedEvent(0)
+			got, err := e.JoinWaitlist(CreateBookingParameters{
+				Invitee:   Invitee{Email: "foo@bar.com"},
+				StartTime: tt.startTime,
+			})
+			assert.True(t, errors.Is(err, tt.wantErr), "JoinWaitlist() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(t, got)
+				assert.Empty(t, e.Waitlist)
+				return
+			}
+			assert.NotEmpty(t, got.ID)
+			assert.Equal(t, WaitlistWaiting, got.Status)
+			assert.Len(t, e.Waitlist, 1)
+		})
+	}
+}
+
+func TestEvent_Waitlist_ImmediatePromotion(t *testing.T) {
+	e, promoted := newFullyBookedEvent(0)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	first, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	second, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "second@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+
+	_, err = e.CancelBooking(e.Bookings[0].ID)
+	assert.NoError(t, err)
+
+	assert.Len(t, *promoted, 1)
+	assert.Equal(t, first.ID, (*promoted)[0].ID)
+	assert.Equal(t, WaitlistPromoted, e.Waitlist[0].Status)
+	assert.Equal(t, WaitlistWaiting, e.Waitlist[1].Status)
+	assert.Equal(t, second.ID, e.Waitlist[1].ID)
+
+	i := e.Bookings.Find(e.Waitlist[0].BookingID)
+	assert.True(t, i >= 0)
+	assert.Equal(t, "first@bar.com", e.Bookings[i].Invitee.Email)
+	assert.Equal(t, 2, e.Bookings.GetBookedCount(slot))
+}
+
+func TestEvent_Waitlist_PromotionWindow(t *testing.T) {
+	e, promoted := newFullyBookedEvent(time.Hour)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+	params := GetSpotParameters{Start: slot, End: slot.Add(time.Hour)}
+
+	first, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	second, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "second@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+
+	_, err = e.CancelBooking(e.Bookings[0].ID)
+	assert.NoError(t, err)
+
+	assert.Len(t, *promoted, 1)
+	assert.Equal(t, WaitlistOffered, e.Waitlist[0].Status)
+	assert.NotZero(t, e.Waitlist[0].OfferExpiresAt)
+
+	// the freed seat is held for the first waitlister
+	spots, err := e.GetAvailableSpots(params)
+	assert.NoError(t, err)
+	assert.Empty(t, spots)
+	_, err = e.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.True(t, errors.Is(err, ErrTimeNotAvailable))
+
+	// first waitlister does not claim in time, offer moves to the next one
+	e.ExpireWaitlistOffers(time.Now().Add(time.Hour + time.Second))
+	assert.Len(t, *promoted, 2)
+	assert.Equal(t, second.ID, (*promoted)[1].ID)
+	assert.Equal(t, WaitlistExpired, e.Waitlist[0].Status)
+	assert.Equal(t, WaitlistOffered, e.Waitlist[1].Status)
+
+	_, err = e.ClaimWaitlistOffer(first.ID)
+	assert.True(t, errors.Is(err, ErrWaitlistOfferNotActive))
+
+	b, err := e.ClaimWaitlistOffer(second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "second@bar.com", b.Invitee.Email)
+	assert.Equal(t, WaitlistPromoted, e.Waitlist[1].Status)
+	assert.Equal(t, b.ID, e.Waitlist[1].BookingID)
+	assert.Equal(t, 2, e.Bookings.GetBookedCount(slot))
+}
+
+func TestEvent_Waitlist_Paused(t *testing.T) {
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	e, promoted := newFullyBookedEvent(0)
+	_, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	e.Paused = true
+	_, err = e.CancelBooking(e.Bookings[0].ID)
+	assert.NoError(t, err)
+	assert.Empty(t, *promoted)
+	assert.Equal(t, WaitlistWaiting, e.Waitlist[0].Status)
+	assert.Equal(t, 1, e.Bookings.GetBookedCount(slot))
+
+	e, _ = newFullyBookedEvent(time.Hour)
+	entry, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(e.Bookings[0].ID)
+	assert.NoError(t, err)
+	e.Paused = true
+	_, err = e.ClaimWaitlistOffer(entry.ID)
+	assert.Equal(t, ErrEventNotAcceptingBookings, err)
+	assert.Equal(t, WaitlistOffered, e.Waitlist[0].Status)
+}
+
+func TestEvent_LeaveWaitlist(t *testing.T) {
+	e, promoted := newFullyBookedEvent(time.Hour)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	first, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	second, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "second@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+
+	_, err = e.CancelBooking(e.Bookings[0].ID)
+	assert.NoError(t, err)
+	assert.NoError(t, e.LeaveWaitlist(first.ID))
+
+	assert.Len(t, *promoted, 2)
+	assert.Equal(t, second.ID, (*promoted)[1].ID)
+	assert.Equal(t, WaitlistLeft, e.Waitlist[0].Status)
+
+	assert.True(t, errors.Is(e.LeaveWaitlist(uuid.New()), ErrWaitlistEntryNotFound))
+}
+
+func TestEvent_Waitlist_HostBookedElsewhere(t *testing.T) {
+	slot := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	clock := clocktest.NewFake(slot.AddDate(0, 0, -1))
+	newEvent := func() *Event {
+		return &Event{
+			Duration: 60 * time.Minute,
+			Availability: map[time.Weekday][]Range{
+				time.Monday: []Range{{StartSec: 9 * 3600, EndSec: 11 * 3600}},
+			},
+			Location:        time.UTC,
+			MaxInvitees:     1,
+			PromotionWindow: time.Hour,
+			Clock:           clock,
+		}
+	}
+	a, b := newEvent(), newEvent()
+	h := NewHost("Foo Bar", "foo@bar.com")
+	h.AddEvent(a)
+	h.AddEvent(b)
+
+	booking, err := a.CreateBooking(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	entry, err := a.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "second@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	_, err = a.CancelBooking(booking.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, WaitlistOffered, a.Waitlist[0].Status)
+
+	// the offer keeps the host busy for the other events
+	_, err = b.CreateBooking(CreateBookingParameters{StartTime: slot})
+	assert.Equal(t, ErrTimeNotAvailable, err)
+
+	// the host got busy before the offer is claimed
+	h.BusyTimes = Intervals{{Start: slot, End: slot.Add(time.Hour)}}
+	_, err = a.ClaimWaitlistOffer(entry.ID)
+	assert.Equal(t, ErrTimeNotAvailable, err)
+	assert.Equal(t, WaitlistExpired, a.Waitlist[0].Status)
+	assert.Empty(t, a.Bookings.Active())
+
+	// nobody is offered a spot the host can not take
+	third, err := a.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "third@bar.com"}, StartTime: slot})
+	assert.Nil(t, third)
+	assert.Equal(t, ErrTimeNotAvailable, err)
+}
+
+func TestEvent_Waitlist_RoundRobin(t *testing.T) {
+	e, a, b, c := newRoundRobinEvent(t)
+	e.MaxInvitees = 2
+	c.BusyTimes[0].End = time.Date(2022, 2, 7, 11, 0, 0, 0, time.UTC)
+	slot := time.Date(2022, 2, 7, 10, 0, 0, 0, time.UTC)
+
+	first, err := e.CreateBooking(CreateBookingParameters{Invitee: Invitee{Email: "first@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	second, err := e.CreateBooking(CreateBookingParameters{Invitee: Invitee{Email: "second@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{a.ID, b.ID}, []uuid.UUID{first.HostID, second.HostID})
+
+	entry, err := e.JoinWaitlist(CreateBookingParameters{Invitee: Invitee{Email: "third@bar.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(second.ID)
+	assert.NoError(t, err)
+
+	assert.Equal(t, WaitlistPromoted, e.Waitlist[0].Status)
+	i := e.Bookings.Find(e.Waitlist[0].BookingID)
+	assert.True(t, i >= 0)
+	assert.Equal(t, entry.Invitee, e.Bookings[i].Invitee)
+	assert.Equal(t, second.HostID, e.Bookings[i].HostID)
+}
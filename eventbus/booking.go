@@ -0,0 +1,33 @@
+package eventbus
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Events finds an event by id. It returns nil for unknown events
+type Events func(id uuid.UUID) *core.Event
+
+// BookingHandler adapts a handler of booking changes, e.g. of notification,
+// webhook or reminder, to the bus. Domain events which are not booking
+// changes are ignored. Changes of unknown events fail, so that they are
+// retried once the event is loaded. The handler reads the event, which is not
+// safe for concurrent use, so it must not be subscribed with SubscribeAsync
+// and its retries must be flushed by the goroutine changing the events
+// rather than by Run
+func BookingHandler(events Events, handle func(e *core.Event, c core.BookingChange) error) Handler {
+	return func(d core.DomainEvent) error {
+		eventID, c, ok := core.BookingChangeOf(d)
+		if !ok {
+			return nil
+		}
+		e := events(eventID)
+		if e == nil {
+			return fmt.Errorf("unknown event %s", eventID)
+		}
+		return handle(e, c)
+	}
+}
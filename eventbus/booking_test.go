@@ -0,0 +1,33 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+func TestBookingHandler(t *testing.T) {
+	known := &core.Event{ID: uuid.New()}
+	events := func(id uuid.UUID) *core.Event {
+		if id == known.ID {
+			return known
+		}
+		return nil
+	}
+	var changes []core.BookingChange
+	h := BookingHandler(events, func(e *core.Event, c core.BookingChange) error {
+		assert.Equal(t, known, e)
+		changes = append(changes, c)
+		return nil
+	})
+
+	b := core.Booking{ID: uuid.New(), StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, h(core.BookingCancelledEvent{EventID: known.ID, Booking: b, Previous: b}))
+	assert.NoError(t, h(core.EventUpdatedEvent{EventID: known.ID}))
+	assert.Error(t, h(core.BookingCreatedEvent{EventID: uuid.New(), Booking: b}))
+	assert.Equal(t, []core.BookingChange{{Kind: core.ChangeCancelled, Booking: b, Previous: b}}, changes)
+}
@@ -0,0 +1,263 @@
+// Package eventbus publishes the domain events of core to subscribers
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Handler handles a domain event. Failed events are retried, so handlers
+// should be idempotent
+type Handler func(d core.DomainEvent) error
+
+type subscriber struct {
+	name   string
+	topic  string
+	async  bool
+	handle Handler
+}
+
+func (s subscriber) matches(topic string) bool {
+	return s.topic == "" || s.topic == topic
+}
+
+// Bus delivers the published domain events to its subscribers. Every event
+// is added to the outbox first and removed once each of its subscribers
+// handled it, so that events are not lost if a subscriber fails. Use
+// FileOutbox to keep them across restarts.
+//
+// Synchronous subscribers are called by Publish, i.e. before the core
+// operation returns. Asynchronous subscribers and failed synchronous ones are
+// called by Flush, which Run calls in the background
+type Bus struct {
+	Outbox Outbox
+
+	// OnError is called with the errors of the subscribers and the outbox
+	OnError func(err error)
+
+	// Clock ticks the retries of Run. SystemClock is used if it is nil
+	Clock core.Clock
+
+	mu          sync.Mutex
+	subscribers []subscriber
+
+	// publishing are the records whose synchronous subscribers are being
+	// called. Flush skips them
+	publishing map[uuid.UUID]bool
+
+	// flushing prevents concurrent flushes from handling the same record
+	flushing sync.Mutex
+	wake     chan struct{}
+}
+
+// New returns a bus keeping its events in the outbox
+func New(outbox Outbox) *Bus {
+	return &Bus{
+		Outbox: outbox,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Subscribe calls the handler with the events of the topic, e.g.
+// booking.created, when they are published. Empty topic subscribes to every
+// event. The name identifies the subscriber in the outbox, so it must be
+// unique and stay the same across restarts
+func (b *Bus) Subscribe(name, topic string, h Handler) {
+	b.subscribe(subscriber{name: name, topic: topic, handle: h})
+}
+
+// SubscribeAsync is like Subscribe but the handler is called in the
+// background by Run. core.Event is not safe for concurrent use, so the
+// handler must only use the domain event, not read the core event it
+// belongs to, e.g. the handlers of BookingHandler must be subscribed with
+// Subscribe
+func (b *Bus) SubscribeAsync(name, topic string, h Handler) {
+	b.subscribe(subscriber{name: name, topic: topic, async: true, handle: h})
+}
+
+func (b *Bus) subscribe(s subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, s)
+}
+
+// Publish adds the event to the outbox and calls the synchronous subscribers.
+// Events without subscribers yet are kept for the ones subscribing later
+func (b *Bus) Publish(d core.DomainEvent) {
+	r := Record{ID: uuid.New(), Event: d, CreatedAt: d.OccurredAt(), Routed: true}
+	b.mu.Lock()
+	var subscribers []subscriber
+	for _, s := range b.subscribers {
+		if s.matches(d.Topic()) {
+			subscribers = append(subscribers, s)
+			r.Pending = append(r.Pending, s.name)
+		}
+	}
+	if len(subscribers) > 0 {
+		if b.publishing == nil {
+			b.publishing = map[uuid.UUID]bool{}
+		}
+		b.publishing[r.ID] = true
+	}
+	b.mu.Unlock()
+	if len(subscribers) == 0 {
+		r.Routed = false
+		if err := b.Outbox.Add(r); err != nil {
+			b.reportError(fmt.Errorf("add %s to outbox: %w", d.Topic(), err))
+		}
+		return
+	}
+	defer func() {
+		b.mu.Lock()
+		delete(b.publishing, r.ID)
+		b.mu.Unlock()
+	}()
+
+	if err := b.Outbox.Add(r); err != nil {
+		// without the outbox the event is only handled once, right away
+		b.reportError(fmt.Errorf("add %s to outbox: %w", d.Topic(), err))
+		for _, s := range subscribers {
+			if err := s.handle(d); err != nil {
+				b.reportError(fmt.Errorf("%s handle %s: %w", s.name, d.Topic(), err))
+			}
+		}
+		return
+	}
+
+	var async bool
+	var pending []string
+	for _, s := range subscribers {
+		if s.async {
+			async = true
+			pending = append(pending, s.name)
+			continue
+		}
+		if err := s.handle(d); err != nil {
+			r.Attempts = 1
+			r.LastError = err.Error()
+			pending = append(pending, s.name)
+			b.reportError(fmt.Errorf("%s handle %s: %w", s.name, d.Topic(), err))
+		}
+	}
+	if len(pending) != len(r.Pending) || r.Attempts > 0 {
+		r.Pending = pending
+		if err := b.Outbox.Update(r); err != nil {
+			b.reportError(fmt.Errorf("update outbox: %w", err))
+		}
+	}
+	if async {
+		b.notify()
+	}
+}
+
+// Flush calls the pending subscribers of the records in the outbox. Events
+// published before any of their subscribers are routed to the current
+// subscribers of their topic
+func (b *Bus) Flush() error {
+	b.flushing.Lock()
+	defer b.flushing.Unlock()
+
+	b.mu.Lock()
+	pending, err := b.Outbox.Pending()
+	var records []Record
+	for _, r := range pending {
+		if !b.publishing[r.ID] {
+			records = append(records, r)
+		}
+	}
+	ordered := append([]subscriber(nil), b.subscribers...)
+	subscribers := map[string]subscriber{}
+	for _, s := range b.subscribers {
+		subscribers[s.name] = s
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("load outbox: %w", err)
+	}
+
+	var firstErr error
+	for _, r := range records {
+		if !r.Routed {
+			for _, s := range ordered {
+				if s.matches(r.Event.Topic()) {
+					r.Pending = append(r.Pending, s.name)
+				}
+			}
+			if len(r.Pending) == 0 {
+				continue
+			}
+			r.Routed = true
+		}
+
+		var pending []string
+		var failed bool
+		for _, name := range r.Pending {
+			s, ok := subscribers[name]
+			if !ok {
+				pending = append(pending, name)
+				continue
+			}
+			if err := s.handle(r.Event); err != nil {
+				failed = true
+				r.LastError = err.Error()
+				pending = append(pending, name)
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s handle %s: %w", name, r.Event.Topic(), err)
+				}
+			}
+		}
+		if failed {
+			r.Attempts++
+		}
+		r.Pending = pending
+		if err := b.Outbox.Update(r); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("update outbox: %w", err)
+		}
+	}
+	return firstErr
+}
+
+// Run flushes the outbox whenever an event is published for an asynchronous
+// subscriber and every retry interval, until the context is done
+func (b *Bus) Run(ctx context.Context, retryInterval time.Duration) error {
+	clock := b.Clock
+	if clock == nil {
+		clock = core.SystemClock
+	}
+	ticker := clock.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.wake:
+		case <-ticker.C():
+		}
+		if err := b.Flush(); err != nil {
+			b.reportError(err)
+		}
+	}
+}
+
+// notify wakes up Run without blocking the publisher
+func (b *Bus) notify() {
+	if b.wake == nil {
+		return
+	}
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Bus) reportError(err error) {
+	if b.OnError != nil {
+		b.OnError(err)
+	}
+}
@@ -0,0 +1,149 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+)
+
+type recordingHandler struct {
+	mu     sync.Mutex
+	topics []string
+	err    error
+}
+
+func (h *recordingHandler) Handle(d core.DomainEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topics = append(h.topics, d.Topic())
+	return h.err
+}
+
+func (h *recordingHandler) Topics() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.topics...)
+}
+
+func TestBus(t *testing.T) {
+	outbox := &MemoryOutbox{}
+	bus := New(outbox)
+	all, cancelled, async := &recordingHandler{}, &recordingHandler{}, &recordingHandler{}
+	bus.Subscribe("all", "", all.Handle)
+	bus.Subscribe("cancelled", "booking.cancelled", cancelled.Handle)
+	bus.SubscribeAsync("analytics", "", async.Handle)
+	e := coretest.NewEvent(time.Hour)
+	e.Publisher = bus
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"booking.created", "booking.cancelled"}, all.Topics())
+	assert.Equal(t, []string{"booking.cancelled"}, cancelled.Topics())
+	assert.Empty(t, async.Topics())
+
+	// only the asynchronous subscriber is still pending
+	records, _ := outbox.Pending()
+	assert.Len(t, records, 2)
+	for _, r := range records {
+		assert.Equal(t, []string{"analytics"}, r.Pending)
+	}
+
+	assert.NoError(t, bus.Flush())
+	assert.Equal(t, []string{"booking.created", "booking.cancelled"}, async.Topics())
+	records, _ = outbox.Pending()
+	assert.Empty(t, records)
+}
+
+func TestBus_FailingSubscriber(t *testing.T) {
+	outbox := &MemoryOutbox{}
+	var errs []error
+	bus := New(outbox)
+	bus.OnError = func(err error) { errs = append(errs, err) }
+	failing, ok := &recordingHandler{err: fmt.Errorf("crm down")}, &recordingHandler{}
+	bus.Subscribe("crm", "", failing.Handle)
+	bus.Subscribe("mailer", "", ok.Handle)
+	e := coretest.NewEvent(time.Hour)
+	e.Publisher = bus
+
+	_, err := e.CreateBooking(core.CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err, "a failing subscriber should not fail the booking")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []string{"booking.created"}, ok.Topics())
+
+	records, _ := outbox.Pending()
+	assert.Len(t, records, 1)
+	assert.Equal(t, []string{"crm"}, records[0].Pending)
+	assert.Equal(t, 1, records[0].Attempts)
+	assert.Equal(t, "crm down", records[0].LastError)
+
+	assert.Error(t, bus.Flush())
+	records, _ = outbox.Pending()
+	assert.Equal(t, 2, records[0].Attempts)
+
+	failing.err = nil
+	assert.NoError(t, bus.Flush())
+	records, _ = outbox.Pending()
+	assert.Empty(t, records)
+	assert.Equal(t, []string{"booking.created", "booking.created", "booking.created"}, failing.Topics())
+	assert.Equal(t, []string{"booking.created"}, ok.Topics(), "handled events should not be delivered again")
+}
+
+func TestBus_Restart(t *testing.T) {
+	outbox := &MemoryOutbox{}
+	bus := New(outbox)
+	bus.SubscribeAsync("analytics", "", (&recordingHandler{}).Handle)
+	e := coretest.NewEvent(time.Hour)
+	e.Publisher = bus
+	e.Update(func(e *core.Event) { e.Name = "renamed" })
+
+	// the subscriber is not subscribed yet after the restart
+	restarted := New(outbox)
+	assert.NoError(t, restarted.Flush())
+	records, _ := outbox.Pending()
+	assert.Len(t, records, 1)
+
+	analytics := &recordingHandler{}
+	restarted.SubscribeAsync("analytics", "", analytics.Handle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go restarted.Run(ctx, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return len(analytics.Topics()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"event.updated"}, analytics.Topics())
+}
+
+func TestBus_NoSubscribers(t *testing.T) {
+	outbox := &MemoryOutbox{}
+	bus := New(outbox)
+	e := coretest.NewEvent(time.Hour)
+	e.Publisher = bus
+	_, err := e.CreateBooking(core.CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	e.Update(func(e *core.Event) { e.Name = "renamed" })
+
+	records, _ := outbox.Pending()
+	assert.Len(t, records, 2)
+	assert.NoError(t, bus.Flush())
+	records, _ = outbox.Pending()
+	assert.Len(t, records, 2, "events should be kept until someone subscribes")
+
+	created := &recordingHandler{}
+	bus.Subscribe("created", "booking.created", created.Handle)
+	assert.NoError(t, bus.Flush())
+	assert.Equal(t, []string{"booking.created"}, created.Topics())
+	records, _ = outbox.Pending()
+	assert.Len(t, records, 1)
+	assert.Equal(t, "event.updated", records[0].Event.Topic())
+}
@@ -0,0 +1,181 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/jsonl"
+)
+
+// FileOutbox keeps the records in a JSON lines file. Every change of a
+// record is appended, and the file is compacted by Pending once records
+// are done
+type FileOutbox struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (o *FileOutbox) Add(r Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return jsonl.Append(o.Path, r)
+}
+
+func (o *FileOutbox) Update(r Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return jsonl.Append(o.Path, r)
+}
+
+func (o *FileOutbox) Pending() ([]Record, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// the last line of a record is its current state
+	var ids []uuid.UUID
+	latest := map[uuid.UUID]Record{}
+	var lines int
+	err := jsonl.Read(o.Path, func(line []byte) error {
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return err
+		}
+		if _, ok := latest[r.ID]; !ok {
+			ids = append(ids, r.ID)
+		}
+		latest[r.ID] = r
+		lines++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, id := range ids {
+		if r := latest[id]; !r.Done() {
+			records = append(records, r)
+		}
+	}
+	if lines > len(records) {
+		if err := o.rewrite(records); err != nil {
+			return nil, fmt.Errorf("compact outbox: %w", err)
+		}
+	}
+	return records, nil
+}
+
+// rewrite replaces the file with the records
+func (o *FileOutbox) rewrite(records []Record) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(o.Path), filepath.Base(o.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), o.Path)
+}
+
+type record struct {
+	ID        uuid.UUID       `json:"id"`
+	Topic     string          `json:"topic"`
+	Event     json.RawMessage `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Pending   []string        `json:"pending,omitempty"`
+	Routed    bool            `json:"routed"`
+	Attempts  int             `json:"attempts,omitempty"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+func (r Record) MarshalJSON() ([]byte, error) {
+	event, err := json.Marshal(r.Event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(record{
+		ID:        r.ID,
+		Topic:     r.Event.Topic(),
+		Event:     event,
+		CreatedAt: r.CreatedAt,
+		Pending:   r.Pending,
+		Routed:    r.Routed,
+		Attempts:  r.Attempts,
+		LastError: r.LastError,
+	})
+}
+
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var s record
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	d, err := unmarshalEvent(s.Topic, s.Event)
+	if err != nil {
+		return err
+	}
+	*r = Record{
+		ID:        s.ID,
+		Event:     d,
+		CreatedAt: s.CreatedAt,
+		Pending:   s.Pending,
+		Routed:    s.Routed,
+		Attempts:  s.Attempts,
+		LastError: s.LastError,
+	}
+	return nil
+}
+
+// unmarshalEvent returns the domain event of the topic
+func unmarshalEvent(topic string, data []byte) (core.DomainEvent, error) {
+	switch topic {
+	case core.BookingCreatedEvent{}.Topic():
+		var d core.BookingCreatedEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	case core.BookingRescheduledEvent{}.Topic():
+		var d core.BookingRescheduledEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	case core.BookingCancelledEvent{}.Topic():
+		var d core.BookingCancelledEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	case core.BookingApprovedEvent{}.Topic():
+		var d core.BookingApprovedEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	case core.BookingDeclinedEvent{}.Topic():
+		var d core.BookingDeclinedEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	case core.WaitlistPromotedEvent{}.Topic():
+		var d core.WaitlistPromotedEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	case core.EventUpdatedEvent{}.Topic():
+		var d core.EventUpdatedEvent
+		err := json.Unmarshal(data, &d)
+		return d, err
+	default:
+		return nil, fmt.Errorf("unknown topic %q", topic)
+	}
+}
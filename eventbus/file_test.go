@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+)
+
+func TestFileOutbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.jsonl")
+
+	bus := New(&FileOutbox{Path: path})
+	bus.Subscribe("crm", "", (&recordingHandler{err: fmt.Errorf("crm down")}).Handle)
+	e := coretest.NewEvent(time.Hour)
+	e.Publisher = bus
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar", Email: "bar@foo.com", Timezone: jakarta},
+		StartTime: time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	e.Update(func(e *core.Event) { e.Name = "renamed" })
+
+	// the failed events survive a restart
+	var handled []core.DomainEvent
+	restarted := New(&FileOutbox{Path: path})
+	restarted.Subscribe("crm", "", func(d core.DomainEvent) error {
+		handled = append(handled, d)
+		return nil
+	})
+	assert.NoError(t, restarted.Flush())
+	assert.Len(t, handled, 2)
+	created := handled[0].(core.BookingCreatedEvent)
+	assert.Equal(t, e.ID, created.EventID)
+	assert.Equal(t, b.ID, created.Booking.ID)
+	assert.Equal(t, "Asia/Jakarta", created.Booking.Invitee.Timezone.String())
+	assert.True(t, b.StartTime.Equal(created.Booking.StartTime))
+	updated := handled[1].(core.EventUpdatedEvent)
+	assert.Equal(t, []core.FieldChange{{Field: "name", Before: "Chat", After: "renamed"}}, updated.Changes)
+
+	// handled records are compacted away
+	records, err := (&FileOutbox{Path: path}).Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Record is a published domain event waiting to be handled
+type Record struct {
+	ID        uuid.UUID
+	Event     core.DomainEvent
+	CreatedAt time.Time
+
+	// Pending are the names of the subscribers which have not handled the
+	// event yet
+	Pending []string
+
+	// Routed is false for events published before any of their subscribers.
+	// Flush routes them to the subscribers of their topic
+	Routed bool
+
+	Attempts  int
+	LastError string
+}
+
+// Outbox keeps the published events until every subscriber handled them.
+// Records are added before any subscriber is called so that a failing
+// subscriber or a crash does not lose events
+type Outbox interface {
+	Add(r Record) error
+
+	// Pending returns the records which are not routed or have pending
+	// subscribers, oldest first
+	Pending() ([]Record, error)
+
+	// Update replaces the record. Routed records without pending subscribers
+	// are removed
+	Update(r Record) error
+}
+
+// Done returns true if every subscriber handled the record
+func (r Record) Done() bool {
+	return r.Routed && len(r.Pending) == 0
+}
+
+// MemoryOutbox keeps the records in memory. Records are lost on restart,
+// FileOutbox keeps them
+type MemoryOutbox struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (o *MemoryOutbox) Add(r Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.records = append(o.records, copyRecord(r))
+	return nil
+}
+
+func (o *MemoryOutbox) Pending() ([]Record, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	records := make([]Record, 0, len(o.records))
+	for _, r := range o.records {
+		records = append(records, copyRecord(r))
+	}
+	return records, nil
+}
+
+func (o *MemoryOutbox) Update(r Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := range o.records {
+		if o.records[i].ID != r.ID {
+			continue
+		}
+		if r.Done() {
+			o.records = append(o.records[:i], o.records[i+1:]...)
+		} else {
+			o.records[i] = copyRecord(r)
+		}
+		return nil
+	}
+	return nil
+}
+
+func copyRecord(r Record) Record {
+	r.Pending = append([]string(nil), r.Pending...)
+	return r
+}
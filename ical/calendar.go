@@ -0,0 +1,274 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Calendar is the VCALENDAR object containing events
+type Calendar struct {
+	Events []Event
+}
+
+// Event is a VEVENT. For a recurring event, Start and End are the first
+// occurrence
+type Event struct {
+	UID     string
+	Summary string
+
+	Start, End time.Time
+	AllDay     bool
+
+	Recurrence *core.Recurrence
+	ExDates    []time.Time
+
+	// RecurrenceID is set when the event overrides a single occurrence of
+	// the recurring event with the same UID
+	RecurrenceID time.Time
+
+	// Transparent events do not block time
+	Transparent bool
+	Cancelled   bool
+}
+
+// Duration returns how long each occurrence of the event takes
+func (e Event) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Parse reads the calendar from r. Floating date times are treated as UTC
+func Parse(r io.Reader) (*Calendar, error) {
+	return ParseInLocation(r, time.UTC)
+}
+
+// ParseInLocation reads the calendar from r. Floating date times and TZIDs
+// which are unknown to the system are interpreted in the given location
+func ParseInLocation(r io.Reader, loc *time.Location) (*Calendar, error) {
+	root, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("expected VCALENDAR but got %s", root.Name)
+	}
+
+	cal := &Calendar{}
+	for _, c := range root.Components {
+		if c.Name != "VEVENT" {
+			continue
+		}
+		e, err := parseEvent(c, loc)
+		if err != nil {
+			return nil, err
+		}
+		cal.Events = append(cal.Events, e)
+	}
+	return cal, nil
+}
+
+// ParseFile reads the calendar stored in an .ics file
+func ParseFile(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+func parseEvent(c *Component, loc *time.Location) (Event, error) {
+	var e Event
+	if p, ok := c.Property("UID"); ok {
+		e.UID = p.Value
+	}
+	if p, ok := c.Property("SUMMARY"); ok {
+		e.Summary = unescapeText(p.Value)
+	}
+	if p, ok := c.Property("TRANSP"); ok {
+		e.Transparent = strings.EqualFold(p.Value, "TRANSPARENT")
+	}
+	if p, ok := c.Property("STATUS"); ok {
+		e.Cancelled = strings.EqualFold(p.Value, "CANCELLED")
+	}
+
+	dtstart, ok := c.Property("DTSTART")
+	if !ok {
+		return Event{}, fmt.Errorf("event %s has no DTSTART", e.UID)
+	}
+	var err error
+	if e.Start, err = parseDateTime(dtstart, loc); err != nil {
+		return Event{}, fmt.Errorf("event %s: %w", e.UID, err)
+	}
+	e.AllDay = isDate(dtstart)
+
+	if p, ok := c.Property("DTEND"); ok {
+		if e.End, err = parseDateTime(p, loc); err != nil {
+			return Event{}, fmt.Errorf("event %s: %w", e.UID, err)
+		}
+	} else if p, ok := c.Property("DURATION"); ok {
+		d, err := parseDuration(p.Value)
+		if err != nil {
+			return Event{}, fmt.Errorf("event %s: %w", e.UID, err)
+		}
+		e.End = e.Start.Add(d)
+	} else if e.AllDay {
+		e.End = e.Start.AddDate(0, 0, 1)
+	} else {
+		e.End = e.Start
+	}
+
+	if p, ok := c.Property("RRULE"); ok {
+		r, err := core.ParseRecurrence(p.Value)
+		if err != nil {
+			return Event{}, fmt.Errorf("event %s: %w", e.UID, err)
+		}
+		e.Recurrence = &r
+	}
+
+	for _, p := range c.PropertiesNamed("EXDATE") {
+		for _, v := range strings.Split(p.Value, ",") {
+			p.Value = v
+			t, err := parseDateTime(p, loc)
+			if err != nil {
+				return Event{}, fmt.Errorf("event %s: %w", e.UID, err)
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	}
+
+	if p, ok := c.Property("RECURRENCE-ID"); ok {
+		if e.RecurrenceID, err = parseDateTime(p, loc); err != nil {
+			return Event{}, fmt.Errorf("event %s: %w", e.UID, err)
+		}
+	}
+	return e, nil
+}
+
+func isDate(p Property) bool {
+	return strings.EqualFold(p.Param("VALUE"), "DATE") || len(p.Value) == 8
+}
+
+// parseDateTime parses DATE or DATE-TIME value honoring its TZID parameter
+func parseDateTime(p Property, loc *time.Location) (time.Time, error) {
+	if tzid := p.Param("TZID"); tzid != "" {
+		if l, err := time.LoadLocation(strings.Trim(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	if isDate(p) {
+		return time.ParseInLocation("20060102", p.Value, loc)
+	}
+	if strings.HasSuffix(p.Value, "Z") {
+		return time.Parse("20060102T150405Z", p.Value)
+	}
+	return time.ParseInLocation("20060102T150405", p.Value, loc)
+}
+
+// parseDuration parses DURATION value such as PT1H30M, P1D or -P2W
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, ch := range s {
+		if ch >= '0' && ch <= '9' {
+			num += string(ch)
+			continue
+		}
+		if ch == 'T' {
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		num = ""
+		switch {
+		case ch == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case ch == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case ch == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case ch == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case ch == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return sign * d, nil
+}
+
+// BusyIntervals returns the merged time blocked by the calendar's events
+// within [from, to). Recurring events are expanded, excluding their EXDATEs
+// and occurrences which are overridden by another event
+func (c Calendar) BusyIntervals(from, to time.Time) core.Intervals {
+	window := core.Interval{Start: from, End: to}
+
+	overridden := map[string][]time.Time{}
+	for _, e := range c.Events {
+		if !e.RecurrenceID.IsZero() {
+			overridden[e.UID] = append(overridden[e.UID], e.RecurrenceID)
+		}
+	}
+
+	var busy core.Intervals
+	for _, e := range c.Events {
+		if e.Transparent || e.Cancelled {
+			continue
+		}
+
+		if e.Recurrence == nil {
+			in := core.Interval{Start: e.Start, End: e.End}
+			if in.Overlaps(window) {
+				busy = append(busy, in)
+			}
+			continue
+		}
+
+		excluded := append(append([]time.Time(nil), e.ExDates...), overridden[e.UID]...)
+		for _, o := range e.Recurrence.Between(e.Start, from.Add(-e.Duration()), to) {
+			if containsTime(excluded, o) {
+				continue
+			}
+			in := core.Interval{Start: o, End: o.Add(e.Duration())}
+			if in.Overlaps(window) {
+				busy = append(busy, in)
+			}
+		}
+	}
+	return busy.Merge()
+}
+
+func containsTime(ts []time.Time, t time.Time) bool {
+	for _, tt := range ts {
+		if tt.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
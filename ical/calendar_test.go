@@ -0,0 +1,159 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+func formatIntervals(is core.Intervals) []string {
+	var s []string
+	for _, in := range is {
+		s = append(s, in.Start.UTC().Format(time.RFC3339)+"/"+in.End.UTC().Format(time.RFC3339))
+	}
+	return s
+}
+
+func TestParseFile(t *testing.T) {
+	cal, err := ParseFile("testdata/busy.ics")
+	assert.NoError(t, err)
+	assert.Len(t, cal.Events, 5)
+
+	standup := cal.Events[0]
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+	assert.Equal(t, "standup@example.com", standup.UID)
+	assert.True(t, time.Date(2022, 2, 1, 9, 0, 0, 0, jktTime).Equal(standup.Start))
+	assert.Equal(t, 15*time.Minute, standup.Duration())
+	assert.Equal(t, "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", standup.Recurrence.String())
+	assert.Len(t, standup.ExDates, 1)
+
+	assert.Equal(t, "Lunch, with the team", cal.Events[2].Summary)
+	assert.True(t, cal.Events[3].Transparent)
+	assert.True(t, cal.Events[4].AllDay)
+	assert.Equal(t, 24*time.Hour, cal.Events[4].Duration())
+}
+
+func TestCalendar_BusyIntervals(t *testing.T) {
+	cal, err := ParseFile("testdata/busy.ics")
+	assert.NoError(t, err)
+
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []string
+	}{
+		{
+			name: "expand recurrences, skip exdate, overridden occurrence and transparent event",
+			from: time.Date(2022, 2, 7, 0, 0, 0, 0, jktTime),
+			to:   time.Date(2022, 2, 12, 0, 0, 0, 0, jktTime),
+			want: []string{
+				"2022-02-07T02:00:00Z/2022-02-07T02:15:00Z",
+				"2022-02-07T05:00:00Z/2022-02-07T06:00:00Z",
+				"2022-02-09T03:00:00Z/2022-02-09T03:15:00Z",
+				"2022-02-10T00:00:00Z/2022-02-11T00:00:00Z",
+				"2022-02-11T02:00:00Z/2022-02-11T02:15:00Z",
+			},
+		},
+		{
+			name: "include occurrence which started before the window",
+			from: time.Date(2022, 2, 7, 9, 10, 0, 0, jktTime),
+			to:   time.Date(2022, 2, 7, 12, 0, 0, 0, jktTime),
+			want: []string{
+				"2022-02-07T02:00:00Z/2022-02-07T02:15:00Z",
+			},
+		},
+		{
+			name: "no busy time on weekend",
+			from: time.Date(2022, 2, 12, 0, 0, 0, 0, jktTime),
+			to:   time.Date(2022, 2, 14, 0, 0, 0, 0, jktTime),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatIntervals(cal.BusyIntervals(tt.from, tt.to)))
+		})
+	}
+}
+
+func TestCalendar_BusySource(t *testing.T) {
+	cal, err := ParseFile("testdata/busy.ics")
+	assert.NoError(t, err)
+
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+	h := core.NewHost("Foo Bar", "foo@bar.com")
+	h.BusySources = []core.BusySource{cal}
+	e := &core.Event{
+		Duration: 15 * time.Minute,
+		Availability: map[time.Weekday][]core.Range{
+			time.Monday: []core.Range{{StartSec: 32400, EndSec: 36000}},
+		},
+		Location:    jktTime,
+		MaxInvitees: 1,
+	}
+	h.AddEvent(e)
+
+	// the daily standup has no end, so it still blocks spots years later
+	monday := time.Date(2030, 1, 7, 0, 0, 0, 0, jktTime)
+	spots, err := e.GetAvailableSpots(core.GetSpotParameters{Start: monday, End: monday.AddDate(0, 0, 1)})
+	assert.NoError(t, err)
+	var starts []string
+	for _, s := range spots {
+		starts = append(starts, s.StartTime.Format("15:04"))
+	}
+	assert.Equal(t, []string{"09:15", "09:30", "09:45"}, starts)
+}
+
+func TestParseInLocation(t *testing.T) {
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+	cal, err := ParseInLocation(strings.NewReader(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:floating",
+		"DTSTART:20220207T090000",
+		"DTEND:20220207T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:unknown-tz",
+		"DTSTART;TZID=Nowhere Standard Time:20220207T110000",
+		"DURATION:PT30M",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")), jktTime)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2022, 2, 7, 9, 0, 0, 0, jktTime).Equal(cal.Events[0].Start))
+	assert.True(t, time.Date(2022, 2, 7, 11, 30, 0, 0, jktTime).Equal(cal.Events[1].End))
+}
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P1D", want: 24 * time.Hour},
+		{value: "P1DT12H", want: 36 * time.Hour},
+		{value: "-P2W", want: -14 * 24 * time.Hour},
+		{value: "PT15S", want: 15 * time.Second},
+		{value: "P1H", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "1H", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDuration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
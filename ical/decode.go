@@ -0,0 +1,188 @@
+// Package ical reads and writes iCalendar (RFC 5545) objects
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Property is a single content line of an iCalendar object
+type Property struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// Param returns the first value of the parameter or empty string
+func (p Property) Param(name string) string {
+	if values := p.Params[strings.ToUpper(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Component is a BEGIN:NAME ... END:NAME block
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// Property returns the first property with the given name
+func (c *Component) Property(name string) (Property, bool) {
+	for _, p := range c.Properties {
+		if p.Name == strings.ToUpper(name) {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// PropertiesNamed returns all properties with the given name
+func (c *Component) PropertiesNamed(name string) []Property {
+	var props []Property
+	for _, p := range c.Properties {
+		if p.Name == strings.ToUpper(name) {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// Decode reads the first component (usually a VCALENDAR) from r
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var stack []*Component
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, err := parseContentLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch p.Name {
+		case "BEGIN":
+			c := &Component{Name: strings.ToUpper(p.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, p.Value)
+			}
+			c := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return c, nil
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property %s outside of component", i+1, p.Name)
+			}
+			c := stack[len(stack)-1]
+			c.Properties = append(c.Properties, p)
+		}
+	}
+	return nil, fmt.Errorf("unexpected end of calendar")
+}
+
+// unfold joins lines which were folded with a leading whitespace
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseContentLine parses name *(";" param) ":" value
+func parseContentLine(line string) (Property, error) {
+	p := Property{Params: map[string][]string{}}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return Property{}, fmt.Errorf("invalid content line %q", line)
+	}
+	p.Name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		line = line[i+1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return Property{}, fmt.Errorf("invalid parameter in %q", line)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var values []string
+		for {
+			var value string
+			if strings.HasPrefix(line, `"`) {
+				end := strings.IndexByte(line[1:], '"')
+				if end < 0 {
+					return Property{}, fmt.Errorf("unterminated quoted parameter %s", name)
+				}
+				value, line = line[1:end+1], line[end+2:]
+			} else {
+				end := strings.IndexAny(line, ",;:")
+				if end < 0 {
+					return Property{}, fmt.Errorf("missing value of %s", p.Name)
+				}
+				value, line = line[:end], line[end:]
+			}
+			values = append(values, value)
+			if !strings.HasPrefix(line, ",") {
+				break
+			}
+			line = line[1:]
+		}
+		p.Params[name] = values
+
+		i = 0
+		if line == "" {
+			return Property{}, fmt.Errorf("missing value of %s", p.Name)
+		}
+	}
+
+	if line[i] != ':' {
+		return Property{}, fmt.Errorf("invalid content line %q", line)
+	}
+	p.Value = line[i+1:]
+	return p, nil
+}
+
+// unescapeText decodes a TEXT value
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	c, err := Decode(strings.NewReader(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"SUMMARY:A very long summary which has been",
+		"  folded into two lines",
+		`ATTENDEE;ROLE=REQ-PARTICIPANT;CN="Bar, Foo";MEMBER="mailto:a@b.com","mailto:c@d.com":mailto:bar@foo.com`,
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, "VCALENDAR", c.Name)
+	assert.Len(t, c.Components, 1)
+
+	event := c.Components[0]
+	summary, ok := event.Property("summary")
+	assert.True(t, ok)
+	assert.Equal(t, "A very long summary which has been folded into two lines", summary.Value)
+
+	attendee, ok := event.Property("ATTENDEE")
+	assert.True(t, ok)
+	assert.Equal(t, "mailto:bar@foo.com", attendee.Value)
+	assert.Equal(t, "Bar, Foo", attendee.Param("cn"))
+	assert.Equal(t, "REQ-PARTICIPANT", attendee.Param("ROLE"))
+	assert.Equal(t, []string{"mailto:a@b.com", "mailto:c@d.com"}, attendee.Params["MEMBER"])
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+	}{
+		{
+			name: "unterminated component",
+			ics:  "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VEVENT\r\n",
+		},
+		{
+			name: "mismatched end",
+			ics:  "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+		},
+		{
+			name: "property outside component",
+			ics:  "SUMMARY:foo\r\n",
+		},
+		{
+			name: "missing value",
+			ics:  "BEGIN:VCALENDAR\r\nSUMMARY;LANGUAGE=en\r\nEND:VCALENDAR\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.ics))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxLineOctets = 75
+
+// Encode writes the component to w using CRLF line endings and folding
+// lines longer than 75 octets
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	if err := encodeComponent(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Marshal returns the encoded component
+func Marshal(c *Component) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Properties {
+		if err := writeLine(w, p.String()); err != nil {
+			return err
+		}
+	}
+	for _, sub := range c.Components {
+		if err := encodeComponent(w, sub); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+// writeLine folds the line without splitting multi-byte characters
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !isRuneStart(line[i]) {
+			i--
+		}
+		if _, err := w.WriteString(line[:i] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[i:]
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// String returns the unfolded content line of the property
+func (p Property) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString(";" + name + "=")
+		for i, v := range p.Params[name] {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if strings.ContainsAny(v, ",;:") {
+				v = `"` + v + `"`
+			}
+			b.WriteString(v)
+		}
+	}
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// NewProperty returns a property with a raw value
+func NewProperty(name, value string) Property {
+	return Property{Name: name, Value: value}
+}
+
+// NewTextProperty returns a property with an escaped TEXT value
+func NewTextProperty(name, value string) Property {
+	return Property{Name: name, Value: escapeText(value)}
+}
+
+// NewDateTimeProperty returns a DATE-TIME property. UTC times are written
+// with Z suffix, others with their location as TZID
+func NewDateTimeProperty(name string, t time.Time) Property {
+	if t.Location() == time.UTC {
+		return Property{Name: name, Value: t.Format("20060102T150405Z")}
+	}
+	return Property{
+		Name:   name,
+		Params: map[string][]string{"TZID": {t.Location().String()}},
+		Value:  t.Format("20060102T150405"),
+	}
+}
+
+// WithParam returns a copy of the property with the parameter set
+func (p Property) WithParam(name string, values ...string) Property {
+	params := map[string][]string{}
+	for k, v := range p.Params {
+		params[k] = v
+	}
+	params[strings.ToUpper(name)] = values
+	p.Params = params
+	return p
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
@@ -0,0 +1,56 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	c := &Component{
+		Name: "VCALENDAR",
+		Components: []*Component{
+			{
+				Name: "VEVENT",
+				Properties: []Property{
+					NewTextProperty("SUMMARY", "Intro; with Foo, Bar\nand friends"),
+					NewTextProperty("DESCRIPTION", strings.Repeat("é", 50)),
+					NewProperty("ATTENDEE", "mailto:foo@bar.com").WithParam("CN", "Bar, Foo"),
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, c))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, buf.String(), `SUMMARY:Intro\; with Foo\, Bar\nand friends`)
+	assert.Contains(t, buf.String(), `ATTENDEE;CN="Bar, Foo":mailto:foo@bar.com`)
+
+	decoded, err := Decode(&buf)
+	assert.NoError(t, err)
+	event := decoded.Components[0]
+
+	summary, _ := event.Property("SUMMARY")
+	assert.Equal(t, "Intro; with Foo, Bar\nand friends", unescapeText(summary.Value))
+	description, _ := event.Property("DESCRIPTION")
+	assert.Equal(t, strings.Repeat("é", 50), unescapeText(description.Value))
+	attendee, _ := event.Property("ATTENDEE")
+	assert.Equal(t, "Bar, Foo", attendee.Param("CN"))
+}
+
+func TestNewDateTimeProperty(t *testing.T) {
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+
+	utc := NewDateTimeProperty("DTSTART", time.Date(2022, 2, 7, 7, 0, 0, 0, time.UTC))
+	assert.Equal(t, "DTSTART:20220207T070000Z", utc.String())
+
+	jkt := NewDateTimeProperty("DTSTART", time.Date(2022, 2, 7, 7, 0, 0, 0, jktTime))
+	assert.Equal(t, "DTSTART;TZID=Asia/Jakarta:20220207T070000", jkt.String())
+}
@@ -0,0 +1,36 @@
+package ical
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Fetch downloads and parses the calendar published at url. webcal:// urls
+// are fetched over http. http.DefaultClient is used if client is nil
+func Fetch(ctx context.Context, client *http.Client, url string) (*Calendar, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if strings.HasPrefix(url, "webcal://") {
+		url = "http://" + strings.TrimPrefix(url, "webcal://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch calendar %s: %s", url, resp.Status)
+	}
+	return Parse(resp.Body)
+}
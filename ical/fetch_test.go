@@ -0,0 +1,35 @@
+package ical
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/busy.ics":
+			w.Header().Set("Content-Type", "text/calendar")
+			http.ServeFile(w, r, "testdata/busy.ics")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cal, err := Fetch(context.Background(), srv.Client(), srv.URL+"/busy.ics")
+	assert.NoError(t, err)
+	assert.Len(t, cal.Events, 5)
+
+	cal, err = Fetch(context.Background(), srv.Client(), strings.Replace(srv.URL, "http://", "webcal://", 1)+"/busy.ics")
+	assert.NoError(t, err)
+	assert.Len(t, cal.Events, 5)
+
+	_, err = Fetch(context.Background(), srv.Client(), srv.URL+"/missing.ics")
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package ical
+
+import (
+	"strings"
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// NewFreeBusy returns a calendar publishing the host's free busy time as a
+// VFREEBUSY. Busy time is written with FBTYPE=BUSY and time outside of the
+// availability with FBTYPE=BUSY-UNAVAILABLE. DTSTAMP is set to now
+func NewFreeBusy(h core.Host, fb core.FreeBusy, now time.Time) *Component {
+	props := []Property{
+		NewProperty("UID", h.ID.String()+"-"+fb.Start.UTC().Format("20060102T150405Z")),
+		NewDateTimeProperty("DTSTAMP", now.UTC()),
+		NewDateTimeProperty("DTSTART", fb.Start.UTC()),
+		NewDateTimeProperty("DTEND", fb.End.UTC()),
+		NewProperty("ORGANIZER", "mailto:"+h.Email).WithParam("CN", h.Name),
+	}
+	if len(fb.Busy) > 0 {
+		props = append(props, NewProperty("FREEBUSY", formatPeriods(fb.Busy)).
+			WithParam("FBTYPE", "BUSY"))
+	}
+	if len(fb.Unavailable) > 0 {
+		props = append(props, NewProperty("FREEBUSY", formatPeriods(fb.Unavailable)).
+			WithParam("FBTYPE", "BUSY-UNAVAILABLE"))
+	}
+
+	cal := newCalendar(MethodPublish)
+	cal.Components = append(cal.Components, &Component{Name: "VFREEBUSY", Properties: props})
+	return cal
+}
+
+// formatPeriods formats the intervals as comma separated explicit UTC periods
+func formatPeriods(is core.Intervals) string {
+	periods := make([]string, 0, len(is))
+	for _, in := range is {
+		periods = append(periods, in.Start.UTC().Format("20060102T150405Z")+"/"+in.End.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(periods, ",")
+}
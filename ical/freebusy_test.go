@@ -0,0 +1,50 @@
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+func TestNewFreeBusy(t *testing.T) {
+	at := func(h int) time.Time {
+		return time.Date(2022, 2, 7, h, 0, 0, 0, time.UTC)
+	}
+	h := core.NewHost("Foo Bar", "foo@bar.com")
+
+	cal := NewFreeBusy(*h, core.FreeBusy{
+		Start: at(0),
+		End:   at(24),
+		Busy: core.Intervals{
+			{Start: at(9), End: at(10)},
+			{Start: at(13), End: at(14)},
+		},
+		Unavailable: core.Intervals{
+			{Start: at(0), End: at(9)},
+		},
+	}, at(8))
+
+	method, _ := cal.Property("METHOD")
+	assert.Equal(t, MethodPublish, method.Value)
+
+	fb := cal.Components[0]
+	assert.Equal(t, "VFREEBUSY", fb.Name)
+	assertProperty(t, fb, "DTSTAMP", "20220207T080000Z")
+	assertProperty(t, fb, "DTSTART", "20220207T000000Z")
+	assertProperty(t, fb, "DTEND", "20220208T000000Z")
+	assertProperty(t, fb, "ORGANIZER", "mailto:foo@bar.com")
+
+	periods := fb.PropertiesNamed("FREEBUSY")
+	assert.Len(t, periods, 2)
+	assert.Equal(t, "BUSY", periods[0].Param("FBTYPE"))
+	assert.Equal(t, "20220207T090000Z/20220207T100000Z,20220207T130000Z/20220207T140000Z", periods[0].Value)
+	assert.Equal(t, "BUSY-UNAVAILABLE", periods[1].Param("FBTYPE"))
+	assert.Equal(t, "20220207T000000Z/20220207T090000Z", periods[1].Value)
+
+	raw, err := Marshal(cal)
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), "FREEBUSY;FBTYPE=BUSY:20220207T090000Z/20220207T100000Z,")
+}
@@ -0,0 +1,209 @@
+package ical
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// iTIP (RFC 5546) methods
+const (
+	MethodPublish = "PUBLISH"
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+const prodID = "-//imrenagi//calendly-demo//EN"
+
+// ContentType returns the MIME type of a calendar object sent with the
+// given method, e.g. as an email attachment
+func ContentType(method string) string {
+	return "text/calendar; charset=utf-8; method=" + method
+}
+
+// NewBookingInvite returns the calendar invite sent to the invitee. The method
+// is REQUEST while the booking is active and CANCEL once it is cancelled.
+// The UID is derived from the booking id so that calendar clients update
+// the same entry on reschedule or cancellation
+func NewBookingInvite(e core.Event, b core.Booking) *Component {
+	method := MethodRequest
+	if !b.IsActive() {
+		method = MethodCancel
+	}
+
+	cal := newCalendar(method)
+	start := b.StartTime.In(location(e.Location))
+	if tz := timezoneFor(start.Location(), start); tz != nil {
+		cal.Components = append(cal.Components, tz)
+	}
+	cal.Components = append(cal.Components, newBookingEvent(e, b))
+	return cal
+}
+
+// NewHostFeed returns a calendar of all upcoming bookings across the host's
+// events, suitable for subscription
+func NewHostFeed(h core.Host, now time.Time) *Component {
+	type entry struct {
+		event   *core.Event
+		booking core.Booking
+	}
+
+	var entries []entry
+	for _, e := range h.Events {
+		for _, b := range e.BookingsOf(h.ID) {
+			if b.StartTime.Add(e.Duration).After(now) {
+				entries = append(entries, entry{event: e, booking: b})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].booking.StartTime.Before(entries[j].booking.StartTime)
+	})
+
+	cal := newCalendar(MethodPublish)
+	cal.Properties = append(cal.Properties, NewTextProperty("X-WR-CALNAME", h.Name))
+
+	seen := map[string]bool{}
+	for _, en := range entries {
+		loc := location(en.event.Location)
+		if seen[loc.String()] {
+			continue
+		}
+		seen[loc.String()] = true
+
+		if tz := timezoneFor(loc, now, entries[len(entries)-1].booking.StartTime); tz != nil {
+			cal.Components = append(cal.Components, tz)
+		}
+	}
+	for _, en := range entries {
+		cal.Components = append(cal.Components, newBookingEvent(*en.event, en.booking))
+	}
+	return cal
+}
+
+// FeedHandler serves the host's upcoming bookings as a subscribable calendar.
+// The clock tells which bookings are upcoming, SystemClock is used if it is
+// nil
+func FeedHandler(h *core.Host, clock core.Clock) http.Handler {
+	if clock == nil {
+		clock = core.SystemClock
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := Marshal(NewHostFeed(*h, clock.Now()))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Write(b)
+	})
+}
+
+func newCalendar(method string) *Component {
+	return &Component{
+		Name: "VCALENDAR",
+		Properties: []Property{
+			NewProperty("VERSION", "2.0"),
+			NewProperty("PRODID", prodID),
+			NewProperty("CALSCALE", "GREGORIAN"),
+			NewProperty("METHOD", method),
+		},
+	}
+}
+
+func newBookingEvent(e core.Event, b core.Booking) *Component {
+	start := b.StartTime.In(location(e.Location))
+
+	status := "CONFIRMED"
+	switch {
+	case b.Status == core.BookingPending:
+		status = "TENTATIVE"
+	case !b.IsActive():
+		status = "CANCELLED"
+	}
+
+	props := []Property{
+		NewProperty("UID", b.ID.String()),
+		NewDateTimeProperty("DTSTAMP", e.Now().UTC()),
+		NewDateTimeProperty("DTSTART", start),
+		NewDateTimeProperty("DTEND", start.Add(e.Duration)),
+		NewProperty("SEQUENCE", strconv.Itoa(b.Sequence)),
+		NewProperty("STATUS", status),
+		NewTextProperty("SUMMARY", e.Name),
+	}
+	if !b.MeetingLocation.IsZero() {
+		props = append(props, NewTextProperty("LOCATION", b.MeetingLocation.String()))
+	}
+	if b.MeetingLocation.Kind == core.VideoLink {
+		// RFC 7986
+		props = append(props, NewProperty("CONFERENCE", b.MeetingLocation.Value).
+			WithParam("VALUE", "URI").
+			WithParam("FEATURE", "VIDEO"))
+	}
+	for _, d := range b.Conference.DialIns {
+		uri := "tel:" + d.Number
+		if d.PIN != "" {
+			uri += ",," + d.PIN
+		}
+		props = append(props, NewProperty("CONFERENCE", uri).
+			WithParam("VALUE", "URI").
+			WithParam("FEATURE", "PHONE").
+			WithParam("LABEL", d.Country))
+	}
+	if !b.CreatedAt.IsZero() {
+		props = append(props, NewDateTimeProperty("CREATED", b.CreatedAt.UTC()))
+	}
+	organizer := e.BookingHost(b)
+	if organizer != nil {
+		props = append(props, NewProperty("ORGANIZER", "mailto:"+organizer.Email).
+			WithParam("CN", organizer.Name))
+	}
+	// every member of a collective event attends the meeting
+	if e.Kind == core.Collective {
+		for _, h := range e.Hosts {
+			if h != organizer {
+				props = append(props, newAttendee(h.Email, h.Name))
+			}
+		}
+	}
+	props = append(props, newAttendee(b.Invitee.Email, b.Invitee.Name))
+
+	return &Component{Name: "VEVENT", Properties: props}
+}
+
+func newAttendee(email, name string) Property {
+	return NewProperty("ATTENDEE", "mailto:"+email).
+		WithParam("CN", name).
+		WithParam("ROLE", "REQ-PARTICIPANT").
+		WithParam("PARTSTAT", "ACCEPTED")
+}
+
+// location returns the location used for DATE-TIME values. Local is not a
+// valid TZID, so it is written as UTC
+func location(loc *time.Location) *time.Location {
+	if loc == nil || loc.String() == "Local" {
+		return time.UTC
+	}
+	return loc
+}
+
+// timezoneFor returns VTIMEZONE covering the year around the given times or
+// nil for UTC
+func timezoneFor(loc *time.Location, times ...time.Time) *Component {
+	if loc == time.UTC {
+		return nil
+	}
+	from, to := times[0], times[0]
+	for _, t := range times {
+		if t.Before(from) {
+			from = t
+		}
+		if t.After(to) {
+			to = t
+		}
+	}
+	return NewTimezone(loc, from.AddDate(0, -6, 0), to.AddDate(0, 6, 0))
+}
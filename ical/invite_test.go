@@ -0,0 +1,251 @@
+package ical
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/clocktest"
+)
+
+func newTestEvent(t *testing.T) (*core.Host, *core.Event) {
+	jktTime, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	e := &core.Event{
+		Name:     "30 min chat",
+		Duration: 30 * time.Minute,
+		Availability: map[time.Weekday][]core.Range{
+			time.Monday: []core.Range{{StartSec: 32400, EndSec: 36000}},
+		},
+		Location:    jktTime,
+		MaxInvitees: 1,
+	}
+	h := core.NewHost("Foo Bar", "foo@bar.com")
+	h.AddEvent(e)
+	return h, e
+}
+
+func TestNewBookingInvite(t *testing.T) {
+	_, e := newTestEvent(t)
+	jktTime := e.Location
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee: core.Invitee{
+			Email:    "bar@foo.com",
+			Name:     "Bar Foo",
+			Timezone: time.UTC,
+		},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, jktTime),
+	})
+	assert.NoError(t, err)
+
+	invite := NewBookingInvite(*e, *b)
+	method, _ := invite.Property("METHOD")
+	assert.Equal(t, MethodRequest, method.Value)
+	assert.Equal(t, "VTIMEZONE", invite.Components[0].Name)
+
+	vevent := invite.Components[1]
+	assertProperty(t, vevent, "UID", b.ID.String())
+	assertProperty(t, vevent, "SEQUENCE", "0")
+	assertProperty(t, vevent, "STATUS", "CONFIRMED")
+	assertProperty(t, vevent, "DTSTART", "20220207T090000")
+	assertProperty(t, vevent, "DTEND", "20220207T093000")
+	assertProperty(t, vevent, "ORGANIZER", "mailto:foo@bar.com")
+	assertProperty(t, vevent, "ATTENDEE", "mailto:bar@foo.com")
+
+	dtstart, _ := vevent.Property("DTSTART")
+	assert.Equal(t, "Asia/Jakarta", dtstart.Param("TZID"))
+
+	b, err = e.RescheduleBooking(b.ID, time.Date(2022, 2, 7, 9, 30, 0, 0, jktTime))
+	assert.NoError(t, err)
+
+	vevent = NewBookingInvite(*e, *b).Components[1]
+	assertProperty(t, vevent, "UID", b.ID.String())
+	assertProperty(t, vevent, "SEQUENCE", "1")
+	assertProperty(t, vevent, "DTSTART", "20220207T093000")
+
+	b, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+
+	invite = NewBookingInvite(*e, *b)
+	method, _ = invite.Property("METHOD")
+	assert.Equal(t, MethodCancel, method.Value)
+	vevent = invite.Components[1]
+	assertProperty(t, vevent, "UID", b.ID.String())
+	assertProperty(t, vevent, "SEQUENCE", "2")
+	assertProperty(t, vevent, "STATUS", "CANCELLED")
+
+	// the invite must be readable by the parser
+	raw, err := Marshal(invite)
+	assert.NoError(t, err)
+	cal, err := Parse(bytes.NewReader(raw))
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2022, 2, 7, 9, 30, 0, 0, jktTime).Equal(cal.Events[0].Start))
+	assert.True(t, cal.Events[0].Cancelled)
+}
+
+func TestNewBookingInvite_Pending(t *testing.T) {
+	_, e := newTestEvent(t)
+	e.RequiresConfirmation = true
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Email: "bar@foo.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+
+	invite := NewBookingInvite(*e, *b)
+	method, _ := invite.Property("METHOD")
+	assert.Equal(t, MethodRequest, method.Value)
+	assertProperty(t, invite.Components[1], "STATUS", "TENTATIVE")
+
+	b, err = e.DeclineBooking(b.ID, "")
+	assert.NoError(t, err)
+
+	invite = NewBookingInvite(*e, *b)
+	method, _ = invite.Property("METHOD")
+	assert.Equal(t, MethodCancel, method.Value)
+	assertProperty(t, invite.Components[1], "SEQUENCE", "1")
+}
+
+func TestNewBookingInvite_Team(t *testing.T) {
+	newTeamEvent := func(kind core.EventKind) *core.Event {
+		e := &core.Event{
+			Name:     "Panel",
+			Duration: 30 * time.Minute,
+			Availability: map[time.Weekday][]core.Range{
+				time.Monday: []core.Range{{StartSec: 32400, EndSec: 36000}},
+			},
+			Location:    time.UTC,
+			MaxInvitees: 1,
+			Kind:        kind,
+		}
+		e.AddTeamMember(core.NewHost("Foo", "foo@bar.com"))
+		e.AddTeamMember(core.NewHost("Baz", "baz@bar.com"))
+		return e
+	}
+	attendees := func(c *Component) []string {
+		var values []string
+		for _, p := range c.PropertiesNamed("ATTENDEE") {
+			values = append(values, p.Value)
+		}
+		return values
+	}
+	slot := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+
+	collective := newTeamEvent(core.Collective)
+	b, err := collective.CreateBooking(core.CreateBookingParameters{Invitee: core.Invitee{Email: "bar@foo.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	vevent := NewBookingInvite(*collective, *b).Components[0]
+	assertProperty(t, vevent, "ORGANIZER", "mailto:foo@bar.com")
+	assert.Equal(t, []string{"mailto:baz@bar.com", "mailto:bar@foo.com"}, attendees(vevent))
+
+	roundRobin := newTeamEvent(core.RoundRobin)
+	roundRobin.Bookings = core.Bookings{{ID: uuid.New(), StartTime: slot.Add(30 * time.Minute), HostID: roundRobin.Hosts[0].ID, CreatedAt: slot}}
+	b, err = roundRobin.CreateBooking(core.CreateBookingParameters{Invitee: core.Invitee{Email: "bar@foo.com"}, StartTime: slot})
+	assert.NoError(t, err)
+	assert.Equal(t, roundRobin.Hosts[1].ID, b.HostID)
+	vevent = NewBookingInvite(*roundRobin, *b).Components[0]
+	assertProperty(t, vevent, "ORGANIZER", "mailto:baz@bar.com")
+	assert.Equal(t, []string{"mailto:bar@foo.com"}, attendees(vevent))
+}
+
+func TestNewBookingInvite_MeetingLocation(t *testing.T) {
+	_, e := newTestEvent(t)
+	e.MeetingLocations = []core.MeetingLocation{
+		{Kind: core.InPerson, Value: "Jl. Sudirman 1, Jakarta"},
+		{Kind: core.VideoLink, Value: "https://meet.example.com/abc"},
+	}
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:         core.Invitee{Email: "bar@foo.com"},
+		StartTime:       time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+		MeetingLocation: e.MeetingLocations[0],
+	})
+	assert.NoError(t, err)
+	vevent := NewBookingInvite(*e, *b).Components[1]
+	assertProperty(t, vevent, "LOCATION", `Jl. Sudirman 1\, Jakarta`)
+	_, ok := vevent.Property("CONFERENCE")
+	assert.False(t, ok)
+
+	b, err = e.CreateBooking(core.CreateBookingParameters{
+		Invitee:         core.Invitee{Email: "bar@foo.com"},
+		StartTime:       time.Date(2022, 2, 7, 9, 30, 0, 0, e.Location),
+		MeetingLocation: e.MeetingLocations[1],
+	})
+	assert.NoError(t, err)
+	vevent = NewBookingInvite(*e, *b).Components[1]
+	assertProperty(t, vevent, "LOCATION", "https://meet.example.com/abc")
+	conference, _ := vevent.Property("CONFERENCE")
+	assert.Equal(t, "https://meet.example.com/abc", conference.Value)
+	assert.Equal(t, "VIDEO", conference.Param("FEATURE"))
+
+	b.Conference = core.Conference{DialIns: []core.DialIn{{Country: "ID", Number: "+62215550100", PIN: "1234"}}}
+	conferences := NewBookingInvite(*e, *b).Components[1].PropertiesNamed("CONFERENCE")
+	assert.Len(t, conferences, 2)
+	assert.Equal(t, "tel:+62215550100,,1234", conferences[1].Value)
+	assert.Equal(t, "PHONE", conferences[1].Param("FEATURE"))
+}
+
+func TestFeedHandler(t *testing.T) {
+	clock := clocktest.NewFake(time.Date(2022, 1, 31, 12, 0, 0, 0, time.UTC))
+	h, e := newTestEvent(t)
+	e.Clock = clock
+	other := &core.Event{
+		Name:     "60 min deep dive",
+		Duration: 60 * time.Minute,
+		Availability: map[time.Weekday][]core.Range{
+			time.Tuesday: []core.Range{{StartSec: 0, EndSec: 3600}},
+		},
+		Location:    time.UTC,
+		MaxInvitees: 1,
+		Clock:       clock,
+	}
+	h.AddEvent(other)
+
+	monday := time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location)
+	tuesday := time.Date(2022, 2, 8, 0, 0, 0, 0, time.UTC)
+
+	first, err := e.CreateBooking(core.CreateBookingParameters{StartTime: monday})
+	assert.NoError(t, err)
+	cancelled, err := e.CreateBooking(core.CreateBookingParameters{StartTime: monday.Add(30 * time.Minute)})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(cancelled.ID)
+	assert.NoError(t, err)
+	second, err := other.CreateBooking(core.CreateBookingParameters{StartTime: tuesday})
+	assert.NoError(t, err)
+
+	feed := func() *Calendar {
+		rec := httptest.NewRecorder()
+		FeedHandler(h, clock).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed.ics", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+
+		cal, err := Parse(rec.Body)
+		assert.NoError(t, err)
+		return cal
+	}
+
+	cal := feed()
+	assert.Len(t, cal.Events, 2)
+	assert.Equal(t, first.ID.String(), cal.Events[0].UID)
+	assert.Equal(t, second.ID.String(), cal.Events[1].UID)
+
+	clock.Set(monday.Add(time.Hour))
+	cal = feed()
+	assert.Len(t, cal.Events, 1)
+	assert.Equal(t, second.ID.String(), cal.Events[0].UID)
+}
+
+func assertProperty(t *testing.T, c *Component, name, value string) {
+	p, ok := c.Property(name)
+	assert.True(t, ok, "missing property %s", name)
+	assert.Equal(t, value, p.Value, "property %s", name)
+}
@@ -0,0 +1,83 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewTimezone returns a VTIMEZONE describing the UTC offsets of loc between
+// from and to. Go does not expose the rules of a location, so every offset
+// transition within the window is written as a separate observance
+func NewTimezone(loc *time.Location, from, to time.Time) *Component {
+	type observance struct {
+		start      time.Time
+		name       string
+		offsetFrom int
+		offsetTo   int
+	}
+
+	name, offset := from.In(loc).Zone()
+	observances := []observance{{start: from, name: name, offsetFrom: offset, offsetTo: offset}}
+	minOffset := offset
+
+	for cur := from; cur.Before(to); cur = cur.Add(24 * time.Hour) {
+		next := cur.Add(24 * time.Hour)
+		_, curOffset := cur.In(loc).Zone()
+		_, nextOffset := next.In(loc).Zone()
+		if curOffset == nextOffset {
+			continue
+		}
+
+		// find the first second with the new offset
+		lo, hi := cur, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == curOffset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		name, offset := hi.In(loc).Zone()
+		observances = append(observances, observance{start: hi, name: name, offsetFrom: curOffset, offsetTo: offset})
+		if offset < minOffset {
+			minOffset = offset
+		}
+	}
+
+	tz := &Component{
+		Name:       "VTIMEZONE",
+		Properties: []Property{NewProperty("TZID", loc.String())},
+	}
+	for _, o := range observances {
+		kind := "STANDARD"
+		if o.offsetTo > minOffset {
+			kind = "DAYLIGHT"
+		}
+		// DTSTART of an observance is the local time before the transition
+		localStart := o.start.UTC().Add(time.Duration(o.offsetFrom) * time.Second)
+		tz.Components = append(tz.Components, &Component{
+			Name: kind,
+			Properties: []Property{
+				NewProperty("DTSTART", localStart.Format("20060102T150405")),
+				NewProperty("TZOFFSETFROM", formatOffset(o.offsetFrom)),
+				NewProperty("TZOFFSETTO", formatOffset(o.offsetTo)),
+				NewProperty("TZNAME", o.name),
+			},
+		})
+	}
+	return tz
+}
+
+// formatOffset formats offset seconds east of UTC as (+|-)HHMM[SS]
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+	if sec := offset % 60; sec != 0 {
+		s += fmt.Sprintf("%02d", sec)
+	}
+	return s
+}
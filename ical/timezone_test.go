@@ -0,0 +1,70 @@
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTimezone(t *testing.T) {
+	type observance struct {
+		Kind, DTStart, From, To, Name string
+	}
+
+	nyTime, _ := time.LoadLocation("America/New_York")
+	jktTime, _ := time.LoadLocation("Asia/Jakarta")
+
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		from, to time.Time
+		want     []observance
+	}{
+		{
+			name: "location with daylight saving time",
+			loc:  nyTime,
+			from: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []observance{
+				{"STANDARD", "20211231T190000", "-0500", "-0500", "EST"},
+				{"DAYLIGHT", "20220313T020000", "-0500", "-0400", "EDT"},
+				{"STANDARD", "20221106T020000", "-0400", "-0500", "EST"},
+			},
+		},
+		{
+			name: "location without transitions",
+			loc:  jktTime,
+			from: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []observance{
+				{"STANDARD", "20220101T070000", "+0700", "+0700", "WIB"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tz := NewTimezone(tt.loc, tt.from, tt.to)
+
+			tzid, _ := tz.Property("TZID")
+			assert.Equal(t, tt.loc.String(), tzid.Value)
+
+			var got []observance
+			for _, c := range tz.Components {
+				dtstart, _ := c.Property("DTSTART")
+				from, _ := c.Property("TZOFFSETFROM")
+				to, _ := c.Property("TZOFFSETTO")
+				name, _ := c.Property("TZNAME")
+				got = append(got, observance{c.Name, dtstart.Value, from.Value, to.Value, name.Value})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_formatOffset(t *testing.T) {
+	assert.Equal(t, "+0700", formatOffset(7*3600))
+	assert.Equal(t, "-0330", formatOffset(-(3*3600 + 30*60)))
+	assert.Equal(t, "+0019", formatOffset(19*60))
+	assert.Equal(t, "+001932", formatOffset(19*60+32))
+}
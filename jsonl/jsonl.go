@@ -0,0 +1,54 @@
+// Package jsonl reads and appends files of JSON values, one per line
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// maxLineSize is the longest line Read accepts
+const maxLineSize = 16 * 1024 * 1024
+
+// Append marshals the values and appends them as lines to the file in a
+// single write, creating the file if needed
+func Append(path string, vs ...interface{}) error {
+	var b []byte
+	for _, v := range vs {
+		line, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b = append(append(b, line...), '\n')
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read calls fn with every line of the file. A missing file has no lines
+func Read(path string, fn func(line []byte) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
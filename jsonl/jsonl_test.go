@@ -0,0 +1,36 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonl")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lines.jsonl")
+
+	assert.NoError(t, Read(path, func(line []byte) error {
+		t.Fatal("missing file should have no lines")
+		return nil
+	}))
+
+	type value struct{ N int }
+	assert.NoError(t, Append(path, value{N: 1}))
+	assert.NoError(t, Append(path, value{N: 2}, value{N: 3}))
+
+	var got []value
+	assert.NoError(t, Read(path, func(line []byte) error {
+		var v value
+		err := json.Unmarshal(line, &v)
+		got = append(got, v)
+		return err
+	}))
+	assert.Equal(t, []value{{1}, {2}, {3}}, got)
+}
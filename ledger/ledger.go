@@ -0,0 +1,143 @@
+package ledger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Ledger records the booking changes of events in its store and projects
+// the bookings from the records. Set it as the Journal of the events, e.g.
+// e.Journal = l, so that every change is recorded before it is made.
+// Restore then rebuilds Event.Bookings from the stream
+type Ledger struct {
+	Store Store
+
+	// SnapshotEvery takes a snapshot of a stream after every n records so
+	// that projections do not replay the whole stream. Zero disables
+	// snapshots
+	SnapshotEvery int
+
+	// OnError is called with the snapshots which can not be saved. The
+	// records are stored anyway
+	OnError func(err error)
+}
+
+// Append records the booking changes of the domain events. Other domain
+// events are ignored
+func (l Ledger) Append(ds ...core.DomainEvent) error {
+	var rs []Record
+	for _, d := range ds {
+		if r, ok := NewRecord(d); ok {
+			rs = append(rs, r)
+		}
+	}
+	return l.AppendRecords(rs...)
+}
+
+// AppendRecords adds the records to the streams of their events
+func (l Ledger) AppendRecords(rs ...Record) error {
+	if len(rs) == 0 {
+		return nil
+	}
+	appended, err := l.Store.Append(rs...)
+	if err != nil {
+		return fmt.Errorf("append records: %w", err)
+	}
+	if l.SnapshotEvery <= 0 {
+		return nil
+	}
+
+	snapshots := map[uuid.UUID]bool{}
+	for _, r := range appended {
+		if r.Seq%int64(l.SnapshotEvery) == 0 && !snapshots[r.EventID] {
+			snapshots[r.EventID] = true
+			if err := l.Snapshot(r.EventID); err != nil && l.OnError != nil {
+				l.OnError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// Snapshot saves the current projection of the event's stream
+func (l Ledger) Snapshot(eventID uuid.UUID) error {
+	snap, err := l.replay(eventID, time.Time{})
+	if err != nil {
+		return err
+	}
+	if err := l.Store.SaveSnapshot(snap); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	return nil
+}
+
+// Bookings returns the current bookings of the event
+func (l Ledger) Bookings(eventID uuid.UUID) (core.Bookings, error) {
+	return l.BookingsAt(eventID, time.Time{})
+}
+
+// BookingsAt returns the bookings of the event as they were at the given
+// time, in the order they were created. Zero at returns the current bookings
+func (l Ledger) BookingsAt(eventID uuid.UUID, at time.Time) (core.Bookings, error) {
+	snap, err := l.replay(eventID, at)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Bookings, nil
+}
+
+// Restore replaces the bookings of the event with its projection, e.g.
+// after a restart
+func (l Ledger) Restore(e *core.Event) error {
+	bookings, err := l.Bookings(e.ID)
+	if err != nil {
+		return err
+	}
+	e.Bookings = bookings
+	return nil
+}
+
+// replay applies the records which happened up to at on the latest snapshot
+// before at. Records retried by the bus may be stored after later ones, so
+// they are filtered rather than read up to the first one after at
+func (l Ledger) replay(eventID uuid.UUID, at time.Time) (Snapshot, error) {
+	snap, ok, err := l.Store.Snapshot(eventID, at)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("load snapshot: %w", err)
+	}
+	if !ok {
+		snap = Snapshot{EventID: eventID}
+	}
+
+	records, err := l.Store.Records(eventID, snap.Seq)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("load records: %w", err)
+	}
+	for _, r := range records {
+		if !at.IsZero() && r.At.After(at) {
+			continue
+		}
+		snap.apply(r)
+	}
+	return snap, nil
+}
+
+// apply updates the projection with the record. Records of an older
+// revision of the booking than the projected one are skipped
+func (s *Snapshot) apply(r Record) {
+	s.Seq = r.Seq
+	if r.At.After(s.At) {
+		s.At = r.At
+	}
+	if i := s.Bookings.Find(r.Booking.ID); i >= 0 {
+		if r.Booking.Sequence >= s.Bookings[i].Sequence {
+			s.Bookings[i] = r.Booking
+		}
+		return
+	}
+	s.Bookings = append(s.Bookings, r.Booking)
+}
@@ -0,0 +1,305 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+)
+
+func newLedgerEvent(l Ledger) *core.Event {
+	e := coretest.NewEvent(time.Hour)
+	e.Journal = l
+	return e
+}
+
+func TestLedger(t *testing.T) {
+	store := &MemoryStore{}
+	l := Ledger{Store: store}
+	e := newLedgerEvent(l)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	first, err := e.CreateBooking(core.CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	e.RequiresConfirmation = true
+	second, err := e.CreateBooking(core.CreateBookingParameters{StartTime: slot.Add(time.Hour)})
+	assert.NoError(t, err)
+	_, err = e.ApproveBooking(second.ID)
+	assert.NoError(t, err)
+	_, err = e.RescheduleBooking(first.ID, slot.Add(2*time.Hour))
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(second.ID)
+	assert.NoError(t, err)
+
+	records, err := store.Records(e.ID, 0)
+	assert.NoError(t, err)
+	var types []RecordType
+	for i, r := range records {
+		assert.Equal(t, int64(i+1), r.Seq)
+		types = append(types, r.Type)
+	}
+	assert.Equal(t, []RecordType{Created, Held, Confirmed, Rescheduled, Cancelled}, types)
+
+	bookings, err := l.Bookings(e.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, e.Bookings, bookings)
+
+	restored := &core.Event{ID: e.ID}
+	assert.NoError(t, l.Restore(restored))
+	assert.Equal(t, e.Bookings, restored.Bookings)
+
+	empty, err := l.Bookings(uuid.New())
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestLedger_ConsistentWithEvent(t *testing.T) {
+	l := Ledger{Store: &MemoryStore{}, SnapshotEvery: 3}
+	e := newLedgerEvent(l)
+	monday := func(week, h int) time.Time {
+		return time.Date(2022, 2, 7+7*week, h, 0, 0, 0, time.UTC)
+	}
+	consistent := func() {
+		bookings, err := l.Bookings(e.ID)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, e.Bookings, bookings)
+	}
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{StartTime: monday(2, 0)})
+	assert.NoError(t, err)
+	_, err = e.JoinWaitlist(core.CreateBookingParameters{StartTime: monday(2, 0)})
+	assert.NoError(t, err)
+	consistent()
+
+	// rejected series stores nothing
+	_, err = e.CreateSeries(core.CreateSeriesParameters{
+		StartTime:  monday(0, 0),
+		Recurrence: core.Recurrence{Freq: core.Weekly, Count: 4},
+	})
+	assert.Error(t, err)
+	consistent()
+
+	series, err := e.CreateSeries(core.CreateSeriesParameters{
+		StartTime:  monday(0, 0),
+		Recurrence: core.Recurrence{Freq: core.Weekly, Count: 4},
+		Mode:       core.SkipConflicts,
+	})
+	assert.NoError(t, err)
+	consistent()
+
+	_, err = e.RescheduleSeries(series.ID, monday(1, 0), monday(1, 1))
+	assert.NoError(t, err)
+	consistent()
+
+	// the waitlister is booked into the freed seat
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, e.Bookings.GetBookedCount(monday(2, 0)))
+	consistent()
+
+	e.RequiresConfirmation = true
+	e.ConfirmationWindow = time.Hour
+	pending, err := e.CreateBooking(core.CreateBookingParameters{StartTime: monday(0, 2)})
+	assert.NoError(t, err)
+	_, err = e.DeclineBooking(pending.ID, "busy")
+	assert.NoError(t, err)
+	_, err = e.CreateBooking(core.CreateBookingParameters{StartTime: monday(0, 2)})
+	assert.NoError(t, err)
+	_, err = e.ExpirePendingBookings(time.Now().Add(2 * time.Hour))
+	assert.NoError(t, err)
+	consistent()
+}
+
+// failingStore fails the first n appends
+type failingStore struct {
+	*MemoryStore
+	n int
+}
+
+func (s *failingStore) Append(rs ...Record) ([]Record, error) {
+	if s.n > 0 {
+		s.n--
+		return nil, fmt.Errorf("store down")
+	}
+	return s.MemoryStore.Append(rs...)
+}
+
+func TestLedger_StoreFails(t *testing.T) {
+	l := Ledger{Store: &failingStore{MemoryStore: &MemoryStore{}, n: 1}}
+	e := newLedgerEvent(l)
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	_, err := e.CreateBooking(core.CreateBookingParameters{StartTime: slot})
+	assert.Error(t, err)
+	assert.Empty(t, e.Bookings)
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+
+	bookings, err := l.Bookings(e.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, e.Bookings, bookings)
+	assert.Equal(t, core.BookingCancelled, bookings[0].Status)
+
+	restored := coretest.NewEvent(time.Hour)
+	restored.ID = e.ID
+	assert.NoError(t, l.Restore(restored))
+	assert.Equal(t, e.Bookings, restored.Bookings)
+}
+
+func TestLedger_BookingsAt(t *testing.T) {
+	eventID, bookingID := uuid.New(), uuid.New()
+	start := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time {
+		return time.Date(2022, 2, 1, hour, 0, 0, 0, time.UTC)
+	}
+	records := []Record{
+		{Type: Created, EventID: eventID, At: at(9), Booking: core.Booking{ID: bookingID, StartTime: start}},
+		{Type: Rescheduled, EventID: eventID, At: at(10), Booking: core.Booking{ID: bookingID, StartTime: start.Add(time.Hour), Sequence: 1}},
+		{Type: Created, EventID: eventID, At: at(11), Booking: core.Booking{ID: uuid.New(), StartTime: start}},
+		{Type: Cancelled, EventID: eventID, At: at(12), Actor: "host@example.com", Booking: core.Booking{ID: bookingID, StartTime: start.Add(time.Hour), Status: core.BookingCancelled, Sequence: 2}},
+	}
+
+	for _, snapshotEvery := range []int{0, 2} {
+		store := &MemoryStore{}
+		l := Ledger{Store: store, SnapshotEvery: snapshotEvery}
+		for _, r := range records {
+			assert.NoError(t, l.AppendRecords(r))
+		}
+		if snapshotEvery > 0 {
+			snap, ok, _ := store.Snapshot(eventID, time.Time{})
+			assert.True(t, ok)
+			assert.Equal(t, int64(4), snap.Seq)
+		}
+
+		tests := []struct {
+			name string
+			at   time.Time
+			want core.Bookings
+		}{
+			{name: "before the first record", at: at(8)},
+			{name: "after creation", at: at(9), want: core.Bookings{records[0].Booking}},
+			{name: "after reschedule", at: at(10).Add(30 * time.Minute), want: core.Bookings{records[1].Booking}},
+			{name: "after second booking", at: at(11), want: core.Bookings{records[1].Booking, records[2].Booking}},
+			{name: "latest", want: core.Bookings{records[3].Booking, records[2].Booking}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := l.BookingsAt(eventID, tt.at)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
+
+func TestLedger_RetriedRecord(t *testing.T) {
+	eventID, bookingID := uuid.New(), uuid.New()
+	start := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time {
+		return time.Date(2022, 2, 1, hour, 0, 0, 0, time.UTC)
+	}
+	created := Record{Type: Created, EventID: eventID, At: at(9), Booking: core.Booking{ID: bookingID, StartTime: start}}
+	cancelled := Record{Type: Cancelled, EventID: eventID, At: at(10), Booking: core.Booking{ID: bookingID, StartTime: start, Status: core.BookingCancelled, Sequence: 1}}
+
+	// the created record failed at first and was retried after the cancel
+	l := Ledger{Store: &MemoryStore{}}
+	assert.NoError(t, l.AppendRecords(cancelled))
+	assert.NoError(t, l.AppendRecords(created))
+
+	got, err := l.Bookings(eventID)
+	assert.NoError(t, err)
+	assert.Equal(t, core.Bookings{cancelled.Booking}, got)
+
+	got, err = l.BookingsAt(eventID, at(9))
+	assert.NoError(t, err)
+	assert.Equal(t, core.Bookings{created.Booking}, got)
+}
+
+func TestRecord_JSON(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	at := time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC)
+	r := Record{
+		Seq:     3,
+		Type:    Declined,
+		EventID: uuid.New(),
+		At:      at,
+		Actor:   "host@example.com",
+		Booking: core.Booking{
+			ID:              uuid.New(),
+			Invitee:         core.Invitee{Name: "Bar", Email: "bar@foo.com", Timezone: jakarta},
+			StartTime:       at.Add(time.Hour),
+			CreatedAt:       at,
+			Answers:         core.Answers{"topic": {"hiring"}},
+			MeetingLocation: core.MeetingLocation{Kind: core.VideoLink, Value: "https://meet.example.com/1"},
+			Conference:      core.Conference{ID: "1", JoinURL: "https://meet.example.com/1", DialIns: []core.DialIn{{Number: "+62215550100", PIN: "1234"}}},
+			Status:          core.BookingDeclined,
+			Sequence:        1,
+			CancelledAt:     at,
+			ExpiresAt:       at.Add(time.Hour),
+			Transitions:     []core.StatusTransition{{From: core.BookingPending, To: core.BookingDeclined, At: at, Reason: "busy"}},
+			HostID:          uuid.New(),
+			SeriesID:        uuid.New(),
+		},
+	}
+
+	data, err := json.Marshal(r)
+	assert.NoError(t, err)
+	var got Record
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, r, got)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ledger")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	l := Ledger{Store: &FileStore{Dir: dir}, SnapshotEvery: 2}
+	e := newLedgerEvent(l)
+	e.Questions = core.Questions{{ID: "topic", Label: "Topic", Type: core.TextQuestion}}
+	slot := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar", Email: "bar@foo.com", Timezone: jakarta},
+		StartTime: slot,
+		Answers:   core.Answers{"topic": {"hiring"}},
+	})
+	assert.NoError(t, err)
+	_, err = e.RescheduleBooking(b.ID, slot.Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+
+	// a new store continues the stream of the files
+	restarted := Ledger{Store: &FileStore{Dir: dir}, SnapshotEvery: 2}
+	bookings, err := restarted.Bookings(e.ID)
+	assert.NoError(t, err)
+	assert.Len(t, bookings, 1)
+	got := bookings[0]
+	assert.Equal(t, b.ID, got.ID)
+	assert.Equal(t, core.BookingCancelled, got.Status)
+	assert.Equal(t, 2, got.Sequence)
+	assert.True(t, slot.Add(time.Hour).Equal(got.StartTime))
+	assert.Equal(t, "Asia/Jakarta", got.Invitee.Timezone.String())
+	assert.Equal(t, core.Answers{"topic": {"hiring"}}, got.Answers)
+	assert.Len(t, got.Transitions, 1)
+
+	_, err = e.CreateBooking(core.CreateBookingParameters{StartTime: slot})
+	assert.NoError(t, err)
+	records, err := restarted.Store.Records(e.ID, 0)
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Equal(t, int64(4), records[3].Seq)
+}
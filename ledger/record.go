@@ -0,0 +1,71 @@
+// Package ledger stores the bookings of events as streams of records and
+// rebuilds the bookings from them
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+type RecordType string
+
+const (
+	Created RecordType = "created"
+	// Held is a booking created pending the host's approval
+	Held        RecordType = "held"
+	Confirmed   RecordType = "confirmed"
+	Rescheduled RecordType = "rescheduled"
+	Cancelled   RecordType = "cancelled"
+	Declined    RecordType = "declined"
+)
+
+// Record is a change of a booking in the stream of its event. It carries the
+// booking as it was after the change, so that projections do not depend on
+// how core applied the change
+type Record struct {
+	// Seq is the position of the record in the stream, starting from 1
+	Seq     int64        `json:"seq"`
+	Type    RecordType   `json:"type"`
+	EventID uuid.UUID    `json:"event_id"`
+	At      time.Time    `json:"at"`
+	Actor   string       `json:"actor,omitempty"`
+	Booking core.Booking `json:"booking"`
+}
+
+// NewRecord returns the record of the domain event. Domain events which are
+// not booking changes are ignored
+func NewRecord(d core.DomainEvent) (Record, bool) {
+	r := Record{At: d.OccurredAt()}
+	switch d := d.(type) {
+	case core.BookingCreatedEvent:
+		r.Type, r.EventID, r.Actor, r.Booking = Created, d.EventID, d.Actor, d.Booking
+		if d.Booking.Status == core.BookingPending {
+			r.Type = Held
+		}
+	case core.BookingApprovedEvent:
+		r.Type, r.EventID, r.Actor, r.Booking = Confirmed, d.EventID, d.Actor, d.Booking
+	case core.BookingRescheduledEvent:
+		r.Type, r.EventID, r.Actor, r.Booking = Rescheduled, d.EventID, d.Actor, d.Booking
+	case core.BookingCancelledEvent:
+		r.Type, r.EventID, r.Actor, r.Booking = Cancelled, d.EventID, d.Actor, d.Booking
+	case core.BookingDeclinedEvent:
+		r.Type, r.EventID, r.Actor, r.Booking = Declined, d.EventID, d.Actor, d.Booking
+	default:
+		return Record{}, false
+	}
+	return r, true
+}
+
+// Snapshot is the projection of a stream up to a record
+type Snapshot struct {
+	EventID uuid.UUID `json:"event_id"`
+
+	// Seq and At are of the last record applied to the snapshot
+	Seq int64     `json:"seq"`
+	At  time.Time `json:"at"`
+
+	Bookings core.Bookings `json:"bookings"`
+}
@@ -0,0 +1,207 @@
+package ledger
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/jsonl"
+)
+
+// Store keeps a stream of records and the snapshots of each event. Records
+// can only be appended
+type Store interface {
+	// Append adds the records at the end of their event's stream, either
+	// all of them or none, and returns them with their Seq
+	Append(rs ...Record) ([]Record, error)
+
+	// Records returns the records of the event's stream after the given seq
+	Records(eventID uuid.UUID, after int64) ([]Record, error)
+
+	SaveSnapshot(s Snapshot) error
+
+	// Snapshot returns the latest snapshot of the event whose last record
+	// happened at or before at. Zero at returns the latest snapshot
+	Snapshot(eventID uuid.UUID, at time.Time) (Snapshot, bool, error)
+}
+
+// FileStore keeps the stream and the snapshots of each event in JSON lines
+// files in the directory
+type FileStore struct {
+	Dir string
+
+	mu   sync.Mutex
+	seqs map[uuid.UUID]int64
+}
+
+// Append writes the records of each event in a single write
+func (s *FileStore) Append(rs ...Record) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seqs := map[uuid.UUID]int64{}
+	var eventIDs []uuid.UUID
+	appended := make([]Record, 0, len(rs))
+	for _, r := range rs {
+		seq, ok := seqs[r.EventID]
+		if !ok {
+			eventIDs = append(eventIDs, r.EventID)
+			var err error
+			if seq, err = s.lastSeq(r.EventID); err != nil {
+				return nil, err
+			}
+		}
+		r.Seq = seq + 1
+		seqs[r.EventID] = r.Seq
+		appended = append(appended, r)
+	}
+
+	for _, eventID := range eventIDs {
+		var lines []interface{}
+		for _, r := range appended {
+			if r.EventID == eventID {
+				lines = append(lines, r)
+			}
+		}
+		if err := jsonl.Append(s.recordsPath(eventID), lines...); err != nil {
+			return nil, err
+		}
+		if s.seqs == nil {
+			s.seqs = map[uuid.UUID]int64{}
+		}
+		s.seqs[eventID] = seqs[eventID]
+	}
+	return appended, nil
+}
+
+func (s *FileStore) lastSeq(eventID uuid.UUID) (int64, error) {
+	if seq, ok := s.seqs[eventID]; ok {
+		return seq, nil
+	}
+	records, err := s.records(eventID, 0)
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+	return records[len(records)-1].Seq, nil
+}
+
+func (s *FileStore) Records(eventID uuid.UUID, after int64) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records(eventID, after)
+}
+
+func (s *FileStore) records(eventID uuid.UUID, after int64) ([]Record, error) {
+	var records []Record
+	err := jsonl.Read(s.recordsPath(eventID), func(line []byte) error {
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return err
+		}
+		if r.Seq > after {
+			records = append(records, r)
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (s *FileStore) SaveSnapshot(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return jsonl.Append(s.snapshotsPath(snap.EventID), snap)
+}
+
+func (s *FileStore) Snapshot(eventID uuid.UUID, at time.Time) (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest Snapshot
+	var found bool
+	err := jsonl.Read(s.snapshotsPath(eventID), func(line []byte) error {
+		var snap Snapshot
+		if err := json.Unmarshal(line, &snap); err != nil {
+			return err
+		}
+		if (at.IsZero() || !snap.At.After(at)) && (!found || snap.Seq > latest.Seq) {
+			latest, found = snap, true
+		}
+		return nil
+	})
+	return latest, found, err
+}
+
+func (s *FileStore) recordsPath(eventID uuid.UUID) string {
+	return filepath.Join(s.Dir, eventID.String()+".records.jsonl")
+}
+
+func (s *FileStore) snapshotsPath(eventID uuid.UUID) string {
+	return filepath.Join(s.Dir, eventID.String()+".snapshots.jsonl")
+}
+
+// MemoryStore keeps the streams in memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[uuid.UUID][]Record
+	snapshots map[uuid.UUID][]Snapshot
+}
+
+func (s *MemoryStore) Append(rs ...Record) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.records == nil {
+		s.records = map[uuid.UUID][]Record{}
+	}
+	appended := make([]Record, 0, len(rs))
+	for _, r := range rs {
+		r.Seq = int64(len(s.records[r.EventID])) + 1
+		s.records[r.EventID] = append(s.records[r.EventID], r)
+		appended = append(appended, r)
+	}
+	return appended, nil
+}
+
+func (s *MemoryStore) Records(eventID uuid.UUID, after int64) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []Record
+	for _, r := range s.records[eventID] {
+		if r.Seq > after {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func (s *MemoryStore) SaveSnapshot(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshots == nil {
+		s.snapshots = map[uuid.UUID][]Snapshot{}
+	}
+	snap.Bookings = append(core.Bookings(nil), snap.Bookings...)
+	s.snapshots[snap.EventID] = append(s.snapshots[snap.EventID], snap)
+	return nil
+}
+
+func (s *MemoryStore) Snapshot(eventID uuid.UUID, at time.Time) (Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest Snapshot
+	var found bool
+	for _, snap := range s.snapshots[eventID] {
+		if (at.IsZero() || !snap.At.After(at)) && (!found || snap.Seq > latest.Seq) {
+			latest, found = snap, true
+		}
+	}
+	latest.Bookings = append(core.Bookings(nil), latest.Bookings...)
+	return latest, found, nil
+}
@@ -0,0 +1,190 @@
+package notification
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+// Recipient is someone notified about a booking
+type Recipient struct {
+	Name  string
+	Email string
+
+	// Role is either "invitee" or "host"
+	Role string
+
+	// Location is the timezone the times are rendered in
+	Location *time.Location
+}
+
+// Notifier emails invitees and hosts when bookings change
+type Notifier struct {
+	Sender Sender
+
+	// From is the sender address, e.g. "Calendly Demo <no-reply@example.com>"
+	From string
+
+	// Templates of each event keyed by event id. DefaultTemplates is used for
+	// the missing ones
+	Templates map[uuid.UUID]Templates
+
+	// OnError is called with errors of notifications sent by Hook
+	OnError func(err error)
+}
+
+// Hook returns the booking hook which sends the notifications, e.g. to be
+// set as the event's OnBookingChange
+func (n *Notifier) Hook() core.BookingHook {
+	return func(e *core.Event, c core.BookingChange) {
+		if err := n.Notify(*e, c); err != nil && n.OnError != nil {
+			n.OnError(err)
+		}
+	}
+}
+
+// Handler returns the bus handler which sends the notifications of the
+// booking changes, e.g. bus.Subscribe("notification", "", n.Handler(events))
+func (n *Notifier) Handler(events eventbus.Events) eventbus.Handler {
+	return eventbus.BookingHandler(events, func(e *core.Event, c core.BookingChange) error {
+		return n.Notify(*e, c)
+	})
+}
+
+// Notify sends an email to everyone concerned by the change, with times in
+// the recipient's timezone
+func (n *Notifier) Notify(e core.Event, c core.BookingChange) error {
+	kind, ok := templateKinds[c.Kind]
+	if !ok {
+		return nil
+	}
+	return n.send(e, kind, c, 0)
+}
+
+// Remind sends the reminder of the booking to the invitee and the hosts
+func (n *Notifier) Remind(e core.Event, b core.Booking, before time.Duration) error {
+	return n.send(e, Reminder, core.BookingChange{Booking: b}, before)
+}
+
+func (n *Notifier) send(e core.Event, kind TemplateKind, c core.BookingChange, before time.Duration) error {
+	t := n.template(e.ID, kind)
+
+	var firstErr error
+	for _, r := range recipients(e, c) {
+		data := newTemplateData(e, c, r)
+		data.Before = before
+		m, err := t.Render(data)
+		if err == nil {
+			m.From = n.From
+			m.Date = e.Now()
+			m.To = []string{(&mail.Address{Name: r.Name, Address: r.Email}).String()}
+			err = n.Sender.Send(m)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+var templateKinds = map[core.ChangeKind]TemplateKind{
+	core.ChangeCreated:     Confirmation,
+	core.ChangeApproved:    Confirmation,
+	core.ChangeRescheduled: Reschedule,
+	core.ChangeCancelled:   Cancellation,
+	core.ChangeDeclined:    Cancellation,
+}
+
+func (n *Notifier) template(eventID uuid.UUID, kind TemplateKind) Template {
+	if t, ok := n.Templates[eventID][kind]; ok {
+		return t
+	}
+	return DefaultTemplates[kind]
+}
+
+// recipients returns the invitee and the hosts of the booking. Hosts are not
+// notified about their own approval or decline
+func recipients(e core.Event, c core.BookingChange) []Recipient {
+	b := c.Booking
+	loc := e.Location
+	if b.Invitee.Timezone != nil {
+		loc = b.Invitee.Timezone
+	}
+	var rs []Recipient
+	if b.Invitee.Email != "" {
+		rs = append(rs, Recipient{Name: b.Invitee.Name, Email: b.Invitee.Email, Role: "invitee", Location: loc})
+	}
+	if c.Kind == core.ChangeApproved || c.Kind == core.ChangeDeclined {
+		return rs
+	}
+
+	seen := map[string]bool{}
+	for _, h := range hostsOf(e, b) {
+		if h.Email == "" || seen[h.Email] {
+			continue
+		}
+		seen[h.Email] = true
+
+		loc := e.Location
+		if h.Schedule != nil && h.Schedule.Location != nil {
+			loc = h.Schedule.Location
+		}
+		rs = append(rs, Recipient{Name: h.Name, Email: h.Email, Role: "host", Location: loc})
+	}
+	return rs
+}
+
+// hostsOf returns the hosts attending the booking
+func hostsOf(e core.Event, b core.Booking) []*core.Host {
+	switch e.Kind {
+	case core.RoundRobin:
+		for _, h := range e.Hosts {
+			if h.ID == b.HostID {
+				return []*core.Host{h}
+			}
+		}
+		return nil
+	case core.Collective:
+		hosts := e.Hosts
+		if e.Host != nil {
+			hosts = append([]*core.Host{e.Host}, hosts...)
+		}
+		return hosts
+	default:
+		if e.Host == nil {
+			return nil
+		}
+		return []*core.Host{e.Host}
+	}
+}
+
+func newTemplateData(e core.Event, c core.BookingChange, r Recipient) TemplateData {
+	b := c.Booking
+	data := TemplateData{
+		Event:           e.Name,
+		Recipient:       r,
+		Invitee:         b.Invitee,
+		Start:           b.StartTime.In(r.Location),
+		End:             b.StartTime.Add(e.Duration).In(r.Location),
+		MeetingLocation: b.MeetingLocation.String(),
+		JoinURL:         b.Conference.JoinURL,
+		Pending:         b.Status == core.BookingPending,
+		Declined:        c.Kind == core.ChangeDeclined,
+		Booking:         b,
+	}
+	if hosts := hostsOf(e, b); len(hosts) > 0 {
+		data.HostName = hosts[0].Name
+		data.HostEmail = hosts[0].Email
+	}
+	if c.Kind == core.ChangeRescheduled {
+		data.PreviousStart = c.Previous.StartTime.In(r.Location)
+	}
+	if n := len(b.Transitions); c.Kind == core.ChangeDeclined && n > 0 {
+		data.Reason = b.Transitions[n-1].Reason
+	}
+	return data
+}
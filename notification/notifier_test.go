@@ -0,0 +1,193 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/clocktest"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+type recordingSender struct {
+	messages []Message
+	err      error
+}
+
+func (s *recordingSender) Send(m Message) error {
+	s.messages = append(s.messages, m)
+	return s.err
+}
+
+func newNotifiedEvent(t *testing.T, n *Notifier) *core.Event {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	assert.NoError(t, err)
+
+	e := coretest.NewEvent(30 * time.Minute)
+	e.Name = "30 min chat"
+	e.Location = jakarta
+	e.MeetingLocations = []core.MeetingLocation{{Kind: core.InPerson, Value: "Jl. Sudirman 1"}}
+	e.OnBookingChange = n.Hook()
+	return e
+}
+
+func TestNotifier(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	sender := &recordingSender{}
+	n := &Notifier{Sender: sender, From: "no-reply@example.com"}
+	e := newNotifiedEvent(t, n)
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com", Timezone: berlin},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 2)
+
+	invitee, host := sender.messages[0], sender.messages[1]
+	assert.Equal(t, []string{`"Bar Invitee" <bar@invitee.com>`}, invitee.To)
+	assert.Equal(t, "Confirmed: 30 min chat on Monday, 7 February 2022 03:00 CET", invitee.Subject)
+	assert.Contains(t, invitee.Text, "30 min chat with Foo Bar is confirmed.")
+	assert.Contains(t, invitee.Text, "Where: Jl. Sudirman 1")
+	assert.Contains(t, invitee.HTML, "<p>Where: Jl. Sudirman 1</p>")
+	assert.Equal(t, []string{`"Foo Bar" <foo@bar.com>`}, host.To)
+	assert.Equal(t, "Confirmed: 30 min chat on Monday, 7 February 2022 09:00 WIB", host.Subject)
+	assert.Contains(t, host.Text, "30 min chat with Bar Invitee is confirmed.")
+
+	sender.messages = nil
+	_, err = e.RescheduleBooking(b.ID, time.Date(2022, 2, 7, 10, 0, 0, 0, e.Location))
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 2)
+	assert.Equal(t, "Rescheduled: 30 min chat on Monday, 7 February 2022 04:00 CET", sender.messages[0].Subject)
+	assert.Contains(t, sender.messages[1].Text, "moved from Monday, 7 February 2022 09:00 WIB to Monday, 7 February 2022 10:00 WIB")
+
+	sender.messages = nil
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 2)
+	assert.Equal(t, "Cancelled: 30 min chat on Monday, 7 February 2022 04:00 CET", sender.messages[0].Subject)
+}
+
+func TestNotifier_Handler(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{Sender: sender, From: "no-reply@example.com"}
+	e := newNotifiedEvent(t, n)
+	e.OnBookingChange = nil
+	bus := eventbus.New(&eventbus.MemoryOutbox{})
+	bus.Subscribe("notification", "", n.Handler(func(id uuid.UUID) *core.Event { return e }))
+	e.Publisher = bus
+
+	_, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 2)
+	assert.Equal(t, "Confirmed: 30 min chat on Monday, 7 February 2022 09:00 WIB", sender.messages[0].Subject)
+}
+
+func TestNotifier_Approval(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{Sender: sender, From: "no-reply@example.com"}
+	e := newNotifiedEvent(t, n)
+	e.RequiresConfirmation = true
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 2)
+	assert.True(t, strings.HasPrefix(sender.messages[0].Subject, "Requested: "))
+
+	sender.messages = nil
+	_, err = e.DeclineBooking(b.ID, "I am on leave")
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 1)
+	assert.True(t, strings.HasPrefix(sender.messages[0].Subject, "Declined: "))
+	assert.Contains(t, sender.messages[0].Text, "has been declined: I am on leave.")
+}
+
+func TestNotifier_EventTemplates(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{Sender: sender, From: "no-reply@example.com"}
+	e := newNotifiedEvent(t, n)
+	e.ID = uuid.New()
+	now := time.Date(2022, 2, 1, 8, 0, 0, 0, time.UTC)
+	e.Clock = clocktest.NewFake(now)
+
+	tmpl, err := ParseTemplate(`See you {{.Start.Format "15:04"}}`, `{{.Recipient.Role}}`, "")
+	assert.NoError(t, err)
+	n.Templates = map[uuid.UUID]Templates{e.ID: {Confirmation: tmpl}}
+
+	_, err = e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Message{
+		From:    "no-reply@example.com",
+		To:      []string{`"Bar Invitee" <bar@invitee.com>`},
+		Subject: "See you 09:00",
+		Text:    "invitee",
+		Date:    now,
+	}, sender.messages[0])
+}
+
+func TestNotifier_Errors(t *testing.T) {
+	sender := &recordingSender{err: fmt.Errorf("connection refused")}
+	var errs []error
+	n := &Notifier{Sender: sender, From: "no-reply@example.com", OnError: func(err error) { errs = append(errs, err) }}
+	e := newNotifiedEvent(t, n)
+
+	_, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	// the booking is kept and every recipient is tried
+	assert.NoError(t, err)
+	assert.Len(t, sender.messages, 2)
+	assert.Equal(t, []error{sender.err}, errs)
+}
+
+func TestNotifier_SMTP(t *testing.T) {
+	srv := newFakeSMTPServer(t)
+	n := &Notifier{Sender: SMTPSender{Addr: srv.Addr}, From: "Calendly Demo <no-reply@example.com>"}
+	e := newNotifiedEvent(t, n)
+
+	_, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+
+	mails := srv.Mails()
+	assert.Len(t, mails, 2)
+	assert.Equal(t, []string{"bar@invitee.com"}, mails[0].To)
+	assert.Equal(t, []string{"foo@bar.com"}, mails[1].To)
+	assert.Contains(t, mails[0].Data, "Subject: Confirmed: 30 min chat on Monday, 7 February 2022 09:00 WIB")
+}
+
+func TestNotifier_Remind(t *testing.T) {
+	sender := &recordingSender{}
+	n := &Notifier{Sender: sender, From: "no-reply@example.com"}
+	e := newNotifiedEvent(t, n)
+
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar Invitee", Email: "bar@invitee.com"},
+		StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, e.Location),
+	})
+	assert.NoError(t, err)
+
+	sender.messages = nil
+	assert.NoError(t, n.Remind(*e, *b, time.Hour))
+	assert.Len(t, sender.messages, 2)
+	assert.Equal(t, "Reminder: 30 min chat on Monday, 7 February 2022 09:00 WIB", sender.messages[1].Subject)
+	assert.Contains(t, sender.messages[1].Text, "This is a reminder of 30 min chat with Bar Invitee.")
+}
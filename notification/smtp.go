@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"net/mail"
+	"net/smtp"
+)
+
+// Sender delivers emails
+type Sender interface {
+	Send(m Message) error
+}
+
+// SMTPSender delivers emails to an SMTP server. STARTTLS is used if the
+// server supports it
+type SMTPSender struct {
+	// Addr is the host:port of the server
+	Addr string
+
+	// Auth is optional, e.g. smtp.PlainAuth
+	Auth smtp.Auth
+}
+
+func (s SMTPSender) Send(m Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+	var to []string
+	for _, addr := range m.To {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return err
+		}
+		to = append(to, a.Address)
+	}
+
+	msg, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(s.Addr, s.Auth, from.Address, to, msg)
+}
@@ -0,0 +1,133 @@
+package notification
+
+import (
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type receivedMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTPServer is an in-process SMTP server keeping the received mails
+type fakeSMTPServer struct {
+	Addr string
+
+	mu    sync.Mutex
+	mails []receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeSMTPServer{Addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) Mails() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.mails...)
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var mail receivedMail
+	tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			mail = receivedMail{From: address(line)}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			mail.To = append(mail.To, address(line))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := ioutil.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			mail.Data = string(data)
+			s.mu.Lock()
+			s.mails = append(s.mails, mail)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+// address returns the address within angle brackets of MAIL and RCPT
+func address(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	srv := newFakeSMTPServer(t)
+	sender := SMTPSender{Addr: srv.Addr}
+
+	err := sender.Send(Message{
+		From:    "Calendly Demo <no-reply@example.com>",
+		To:      []string{"Bar Foo <bar@foo.com>"},
+		Subject: "Confirmed: Demo – 30 min",
+		Text:    "Hi Bar,\n\nSee you.",
+		HTML:    "<p>Hi Bar,</p>",
+		Date:    time.Date(2022, 2, 1, 8, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	mails := srv.Mails()
+	assert.Len(t, mails, 1)
+	assert.Equal(t, "no-reply@example.com", mails[0].From)
+	assert.Equal(t, []string{"bar@foo.com"}, mails[0].To)
+	assert.Contains(t, mails[0].Data, "Subject: =?utf-8?q?Confirmed:_Demo_=E2=80=93_30_min?=")
+	assert.Contains(t, mails[0].Data, "Content-Type: multipart/alternative")
+	assert.Contains(t, mails[0].Data, "<p>Hi Bar,</p>")
+	assert.Contains(t, mails[0].Data, "Date: Tue, 01 Feb 2022 08:00:00 +0000")
+
+	err = sender.Send(Message{From: "Calendly Demo <no-reply@example.com>", To: []string{"bar@foo.com"}})
+	assert.Equal(t, ErrNoDate, err)
+
+	err = sender.Send(Message{From: "not an address", To: []string{"bar@foo.com"}, Date: time.Now()})
+	assert.Error(t, err)
+}
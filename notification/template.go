@@ -0,0 +1,176 @@
+package notification
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+type TemplateKind int
+
+const (
+	Confirmation TemplateKind = iota + 1
+	Reschedule
+	Cancellation
+	Reminder
+)
+
+// Template renders an email. HTML is optional
+type Template struct {
+	Subject *texttemplate.Template
+	Text    *texttemplate.Template
+	HTML    *htmltemplate.Template
+}
+
+// Templates stores the template of each kind of email
+type Templates map[TemplateKind]Template
+
+// TemplateData is passed to the templates. Times are in the recipient's
+// timezone
+type TemplateData struct {
+	Event     string
+	Recipient Recipient
+	Invitee   core.Invitee
+	HostName  string
+	HostEmail string
+
+	Start, End time.Time
+
+	// PreviousStart is the start time before the booking was rescheduled
+	PreviousStart time.Time
+
+	// Before is how long before the meeting a reminder is sent
+	Before time.Duration
+
+	MeetingLocation string
+	JoinURL         string
+
+	// Pending is true if the booking waits for the host's approval
+	Pending bool
+
+	// Declined is true if the host declined the booking or it expired
+	// before approval. Reason is given by the host
+	Declined bool
+	Reason   string
+
+	Booking core.Booking
+}
+
+var funcs = map[string]interface{}{
+	"datetime": func(t time.Time) string {
+		return t.Format("Monday, 2 January 2006 15:04 MST")
+	},
+}
+
+// ParseTemplate parses the templates of an email. The datetime function
+// formats a time, e.g. {{datetime .Start}}
+func ParseTemplate(subject, text, html string) (Template, error) {
+	var t Template
+	var err error
+	if t.Subject, err = texttemplate.New("subject").Funcs(funcs).Parse(subject); err != nil {
+		return Template{}, err
+	}
+	if t.Text, err = texttemplate.New("text").Funcs(funcs).Parse(text); err != nil {
+		return Template{}, err
+	}
+	if html != "" {
+		if t.HTML, err = htmltemplate.New("html").Funcs(funcs).Parse(html); err != nil {
+			return Template{}, err
+		}
+	}
+	return t, nil
+}
+
+// Render returns the message for the data without sender and recipients
+func (t Template) Render(data TemplateData) (Message, error) {
+	var m Message
+	var buf bytes.Buffer
+	if err := t.Subject.Execute(&buf, data); err != nil {
+		return Message{}, err
+	}
+	m.Subject = buf.String()
+
+	buf.Reset()
+	if err := t.Text.Execute(&buf, data); err != nil {
+		return Message{}, err
+	}
+	m.Text = buf.String()
+
+	if t.HTML != nil {
+		buf.Reset()
+		if err := t.HTML.Execute(&buf, data); err != nil {
+			return Message{}, err
+		}
+		m.HTML = buf.String()
+	}
+	return m, nil
+}
+
+func mustParseTemplate(subject, text, html string) Template {
+	t, err := ParseTemplate(subject, text, html)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// DefaultTemplates are used for the kinds an event has no template for
+var DefaultTemplates = Templates{
+	Confirmation: mustParseTemplate(
+		`{{if .Pending}}Requested{{else}}Confirmed{{end}}: {{.Event}} on {{datetime .Start}}`,
+		`Hi {{.Recipient.Name}},
+
+{{if .Pending}}{{.Invitee.Name}} requested {{.Event}}. It is confirmed once approved by the host.{{else}}{{.Event}} with {{if eq .Recipient.Role "host"}}{{.Invitee.Name}}{{else}}{{.HostName}}{{end}} is confirmed.{{end}}
+
+When: {{datetime .Start}} - {{.End.Format "15:04 MST"}}
+{{if .MeetingLocation}}Where: {{.MeetingLocation}}
+{{end}}`,
+		`<p>Hi {{.Recipient.Name}},</p>
+<p>{{if .Pending}}{{.Invitee.Name}} requested {{.Event}}. It is confirmed once approved by the host.{{else}}{{.Event}} with {{if eq .Recipient.Role "host"}}{{.Invitee.Name}}{{else}}{{.HostName}}{{end}} is confirmed.{{end}}</p>
+<p>When: {{datetime .Start}} - {{.End.Format "15:04 MST"}}</p>
+{{if .JoinURL}}<p>Where: <a href="{{.JoinURL}}">{{.JoinURL}}</a></p>
+{{else if .MeetingLocation}}<p>Where: {{.MeetingLocation}}</p>
+{{end}}`,
+	),
+	Reschedule: mustParseTemplate(
+		`Rescheduled: {{.Event}} on {{datetime .Start}}`,
+		`Hi {{.Recipient.Name}},
+
+{{.Event}} has been moved from {{datetime .PreviousStart}} to {{datetime .Start}}.
+{{if .MeetingLocation}}Where: {{.MeetingLocation}}
+{{end}}`,
+		`<p>Hi {{.Recipient.Name}},</p>
+<p>{{.Event}} has been moved from <s>{{datetime .PreviousStart}}</s> to {{datetime .Start}}.</p>
+{{if .MeetingLocation}}<p>Where: {{.MeetingLocation}}</p>
+{{end}}`,
+	),
+	Reminder: mustParseTemplate(
+		`Reminder: {{.Event}} on {{datetime .Start}}`,
+		`Hi {{.Recipient.Name}},
+
+This is a reminder of {{.Event}} with {{if eq .Recipient.Role "host"}}{{.Invitee.Name}}{{else}}{{.HostName}}{{end}}.
+
+When: {{datetime .Start}} - {{.End.Format "15:04 MST"}}
+{{if .MeetingLocation}}Where: {{.MeetingLocation}}
+{{end}}`,
+		`<p>Hi {{.Recipient.Name}},</p>
+<p>This is a reminder of {{.Event}} with {{if eq .Recipient.Role "host"}}{{.Invitee.Name}}{{else}}{{.HostName}}{{end}}.</p>
+<p>When: {{datetime .Start}} - {{.End.Format "15:04 MST"}}</p>
+{{if .JoinURL}}<p>Where: <a href="{{.JoinURL}}">{{.JoinURL}}</a></p>
+{{else if .MeetingLocation}}<p>Where: {{.MeetingLocation}}</p>
+{{end}}`,
+	),
+	Cancellation: mustParseTemplate(
+		`{{if .Declined}}Declined{{else}}Cancelled{{end}}: {{.Event}} on {{datetime .Start}}`,
+		`Hi {{.Recipient.Name}},
+
+{{.Event}} on {{datetime .Start}} has been {{if .Declined}}declined{{if .Reason}}: {{.Reason}}{{end}}{{else}}cancelled{{end}}.
+`,
+		`<p>Hi {{.Recipient.Name}},</p>
+<p>{{.Event}} on {{datetime .Start}} has been {{if .Declined}}declined{{if .Reason}}: {{.Reason}}{{end}}{{else}}cancelled{{end}}.</p>
+`,
+	),
+}
@@ -0,0 +1,241 @@
+// Package reminder sends reminders before booked meetings
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+const defaultMaxAttempts = 3
+
+// Job is a reminder waiting to be sent
+type Job struct {
+	EventID   uuid.UUID     `json:"event_id"`
+	BookingID uuid.UUID     `json:"booking_id"`
+	Before    time.Duration `json:"before"`
+	DueAt     time.Time     `json:"due_at"`
+	Attempts  int           `json:"attempts"`
+}
+
+// start returns the meeting start the job was scheduled for
+func (j Job) start() time.Time {
+	return j.DueAt.Add(j.Before)
+}
+
+// Sender delivers a reminder, e.g. notification.Notifier
+type Sender interface {
+	Remind(e core.Event, b core.Booking, before time.Duration) error
+}
+
+// Scheduler sends a reminder for each offset before confirmed bookings of
+// the watched events. Jobs are kept in the store and updated whenever a
+// booking changes. Events are not safe for concurrent use, so RunDue must
+// not run while the watched events are changed
+type Scheduler struct {
+	Sender Sender
+	Store  Store
+
+	// Offsets are how long before the meeting reminders are sent, e.g.
+	// 24h and 1h
+	Offsets []time.Duration
+
+	// MaxAttempts is how many times sending a reminder is tried. Zero
+	// means 3
+	MaxAttempts int
+
+	// OnError is called with errors of the booking hook
+	OnError func(err error)
+
+	// Clock ticks Run. SystemClock is used if it is nil
+	Clock core.Clock
+
+	mu     sync.Mutex
+	jobs   []Job
+	events map[uuid.UUID]*core.Event
+	lookup eventbus.Events
+}
+
+// NewScheduler returns a scheduler with the jobs saved in the store
+func NewScheduler(sender Sender, store Store, offsets ...time.Duration) (*Scheduler, error) {
+	jobs, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load reminders: %w", err)
+	}
+	return &Scheduler{
+		Sender:  sender,
+		Store:   store,
+		Offsets: offsets,
+		jobs:    jobs,
+		events:  map[uuid.UUID]*core.Event{},
+	}, nil
+}
+
+// Watch registers the event so that its jobs can be sent and chains the
+// scheduler's hook to the event's OnBookingChange. Events must be watched
+// again after a restart
+func (s *Scheduler) Watch(e *core.Event) {
+	s.mu.Lock()
+	s.watch(e)
+	s.mu.Unlock()
+	e.OnBookingChange = core.ChainBookingHooks(e.OnBookingChange, s.Hook())
+}
+
+// Hook returns the booking hook scheduling the reminders of confirmed
+// bookings and removing the ones of bookings which are not taking place
+func (s *Scheduler) Hook() core.BookingHook {
+	return func(e *core.Event, c core.BookingChange) {
+		if err := s.Schedule(e, c.Booking, e.Now()); err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+	}
+}
+
+// Handler returns the bus handler scheduling the reminders like Hook, e.g.
+// bus.Subscribe("reminder", "", s.Handler(events)). The events of the jobs
+// are then found with events instead of being watched
+func (s *Scheduler) Handler(events eventbus.Events) eventbus.Handler {
+	s.mu.Lock()
+	s.lookup = events
+	s.mu.Unlock()
+	return eventbus.BookingHandler(events, func(e *core.Event, c core.BookingChange) error {
+		return s.Schedule(e, c.Booking, e.Now())
+	})
+}
+
+// Schedule replaces the jobs of the booking with the reminders due after now
+func (s *Scheduler) Schedule(e *core.Event, b core.Booking, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watch(e)
+	s.removeJobs(b.ID)
+	if b.Status == core.BookingConfirmed {
+		for _, before := range s.Offsets {
+			due := b.StartTime.Add(-before)
+			if !due.After(now) {
+				continue
+			}
+			s.jobs = append(s.jobs, Job{EventID: e.ID, BookingID: b.ID, Before: before, DueAt: due})
+		}
+	}
+	return s.save()
+}
+
+// Jobs returns the pending jobs sorted by due time
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := append([]Job(nil), s.jobs...)
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].DueAt.Before(jobs[j].DueAt)
+	})
+	return jobs
+}
+
+// RunDue sends the reminders due at now. Failed reminders are retried on the
+// next run until MaxAttempts. Reminders of meetings which already started
+// or whose booking changed are dropped
+func (s *Scheduler) RunDue(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	var firstErr error
+	var pending []Job
+	for _, j := range s.jobs {
+		if j.DueAt.After(now) {
+			pending = append(pending, j)
+			continue
+		}
+		if !j.start().After(now) {
+			continue
+		}
+		e, ok := s.events[j.EventID]
+		if !ok && s.lookup != nil {
+			e = s.lookup(j.EventID)
+			ok = e != nil
+		}
+		if !ok {
+			// the event is not watched yet, e.g. after a restart
+			pending = append(pending, j)
+			continue
+		}
+		i := e.Bookings.Find(j.BookingID)
+		if i < 0 || e.Bookings[i].Status != core.BookingConfirmed || !e.Bookings[i].StartTime.Equal(j.start()) {
+			continue
+		}
+
+		if err := s.Sender.Remind(*e, e.Bookings[i], j.Before); err != nil {
+			j.Attempts++
+			if j.Attempts < maxAttempts {
+				pending = append(pending, j)
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("remind booking %s: %w", j.BookingID, err)
+			}
+		}
+	}
+	s.jobs = pending
+
+	if err := s.save(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// Run sends the due reminders every interval until the context is done
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	clock := s.Clock
+	if clock == nil {
+		clock = core.SystemClock
+	}
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C():
+			if err := s.RunDue(now); err != nil && s.OnError != nil {
+				s.OnError(err)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) watch(e *core.Event) {
+	if s.events == nil {
+		s.events = map[uuid.UUID]*core.Event{}
+	}
+	s.events[e.ID] = e
+}
+
+func (s *Scheduler) removeJobs(bookingID uuid.UUID) {
+	jobs := s.jobs[:0]
+	for _, j := range s.jobs {
+		if j.BookingID != bookingID {
+			jobs = append(jobs, j)
+		}
+	}
+	s.jobs = jobs
+}
+
+func (s *Scheduler) save() error {
+	if err := s.Store.Save(s.jobs); err != nil {
+		return fmt.Errorf("save reminders: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/clocktest"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+type reminded struct {
+	BookingID uuid.UUID
+	Before    time.Duration
+}
+
+type recordingSender struct {
+	reminders []reminded
+	err       error
+}
+
+func (s *recordingSender) Remind(e core.Event, b core.Booking, before time.Duration) error {
+	s.reminders = append(s.reminders, reminded{BookingID: b.ID, Before: before})
+	return s.err
+}
+
+func TestScheduler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reminder")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := FileStore{Path: filepath.Join(dir, "jobs.json")}
+
+	sender := &recordingSender{}
+	s, err := NewScheduler(sender, store, 24*time.Hour, time.Hour)
+	assert.NoError(t, err)
+	e := coretest.NewEvent(time.Hour)
+	s.Watch(e)
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	b, err := e.CreateBooking(core.CreateBookingParameters{StartTime: start})
+	assert.NoError(t, err)
+	assert.Equal(t, []Job{
+		{EventID: e.ID, BookingID: b.ID, Before: 24 * time.Hour, DueAt: start.Add(-24 * time.Hour)},
+		{EventID: e.ID, BookingID: b.ID, Before: time.Hour, DueAt: start.Add(-time.Hour)},
+	}, s.Jobs())
+
+	// reminders follow the booking
+	start = start.Add(2 * time.Hour)
+	_, err = e.RescheduleBooking(b.ID, start)
+	assert.NoError(t, err)
+	jobs := s.Jobs()
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, start.Add(-24*time.Hour), jobs[0].DueAt)
+	assert.Equal(t, start.Add(-time.Hour), jobs[1].DueAt)
+
+	// jobs survive a restart
+	restarted, err := NewScheduler(sender, store, 24*time.Hour, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, len(jobs), len(restarted.Jobs()))
+
+	// not watched events keep their jobs
+	assert.NoError(t, restarted.RunDue(start.Add(-23*time.Hour)))
+	assert.Empty(t, sender.reminders)
+	assert.Len(t, restarted.Jobs(), 2)
+
+	restarted.Watch(e)
+	assert.NoError(t, restarted.RunDue(start.Add(-23*time.Hour)))
+	assert.Equal(t, []reminded{{BookingID: b.ID, Before: 24 * time.Hour}}, sender.reminders)
+	assert.Len(t, restarted.Jobs(), 1)
+
+	saved, err := store.Load()
+	assert.NoError(t, err)
+	assert.Len(t, saved, 1)
+
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, restarted.Jobs())
+}
+
+func TestScheduler_Handler(t *testing.T) {
+	store := &MemoryStore{}
+	sender := &recordingSender{}
+	s, err := NewScheduler(sender, store, time.Hour)
+	assert.NoError(t, err)
+	e := coretest.NewEvent(time.Hour)
+	events := func(id uuid.UUID) *core.Event {
+		if id == e.ID {
+			return e
+		}
+		return nil
+	}
+	bus := eventbus.New(&eventbus.MemoryOutbox{})
+	bus.Subscribe("reminder", "", s.Handler(events))
+	e.Publisher = bus
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	b, err := e.CreateBooking(core.CreateBookingParameters{StartTime: start})
+	assert.NoError(t, err)
+	assert.Len(t, s.Jobs(), 1)
+
+	// the event of the jobs is found without watching it after a restart
+	restarted, err := NewScheduler(sender, store, time.Hour)
+	assert.NoError(t, err)
+	restarted.Handler(events)
+	assert.NoError(t, restarted.RunDue(start.Add(-time.Hour)))
+	assert.Equal(t, []reminded{{BookingID: b.ID, Before: time.Hour}}, sender.reminders)
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	e := coretest.NewEvent(time.Hour)
+	start := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		status  core.BookingStatus
+		now     time.Time
+		wantDue []time.Time
+	}{
+		{
+			name:    "should schedule every reminder",
+			status:  core.BookingConfirmed,
+			now:     start.Add(-48 * time.Hour),
+			wantDue: []time.Time{start.Add(-24 * time.Hour), start.Add(-time.Hour)},
+		},
+		{
+			name:    "should skip reminders which are already due",
+			status:  core.BookingConfirmed,
+			now:     start.Add(-2 * time.Hour),
+			wantDue: []time.Time{start.Add(-time.Hour)},
+		},
+		{
+			name:   "should not remind pending booking",
+			status: core.BookingPending,
+			now:    start.Add(-48 * time.Hour),
+		},
+		{
+			name:   "should not remind cancelled booking",
+			status: core.BookingCancelled,
+			now:    start.Add(-48 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScheduler(&recordingSender{}, &MemoryStore{}, 24*time.Hour, time.Hour)
+			assert.NoError(t, err)
+
+			err = s.Schedule(e, core.Booking{ID: uuid.New(), StartTime: start, Status: tt.status}, tt.now)
+			assert.NoError(t, err)
+			var due []time.Time
+			for _, j := range s.Jobs() {
+				due = append(due, j.DueAt)
+			}
+			assert.Equal(t, tt.wantDue, due)
+		})
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	start := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	newScheduler := func(sender Sender) (*Scheduler, *core.Event, core.Booking) {
+		e := coretest.NewEvent(time.Hour)
+		b := core.Booking{ID: uuid.New(), StartTime: start}
+		e.Bookings = core.Bookings{b}
+		s, _ := NewScheduler(sender, &MemoryStore{}, time.Hour)
+		s.Watch(e)
+		assert.NoError(t, s.Schedule(e, b, start.Add(-2*time.Hour)))
+		return s, e, b
+	}
+
+	t.Run("should retry failed reminders until max attempts", func(t *testing.T) {
+		sender := &recordingSender{err: fmt.Errorf("smtp unavailable")}
+		s, _, _ := newScheduler(sender)
+		s.MaxAttempts = 2
+
+		assert.Error(t, s.RunDue(start.Add(-time.Hour)))
+		assert.Len(t, s.Jobs(), 1)
+		assert.Equal(t, 1, s.Jobs()[0].Attempts)
+
+		assert.Error(t, s.RunDue(start.Add(-59*time.Minute)))
+		assert.Empty(t, s.Jobs())
+		assert.Len(t, sender.reminders, 2)
+	})
+
+	t.Run("should drop reminders of meetings which already started", func(t *testing.T) {
+		sender := &recordingSender{}
+		s, _, _ := newScheduler(sender)
+
+		assert.NoError(t, s.RunDue(start))
+		assert.Empty(t, s.Jobs())
+		assert.Empty(t, sender.reminders)
+	})
+
+	t.Run("should drop reminders of bookings changed without hook", func(t *testing.T) {
+		sender := &recordingSender{}
+		s, e, _ := newScheduler(sender)
+		e.Bookings[0].StartTime = start.Add(time.Hour)
+
+		assert.NoError(t, s.RunDue(start.Add(-time.Hour)))
+		assert.Empty(t, s.Jobs())
+		assert.Empty(t, sender.reminders)
+	})
+}
+
+type senderFunc func(e core.Event, b core.Booking, before time.Duration) error
+
+func (f senderFunc) Remind(e core.Event, b core.Booking, before time.Duration) error {
+	return f(e, b, before)
+}
+
+func TestScheduler_Run(t *testing.T) {
+	clock := clocktest.NewFake(time.Date(2022, 2, 6, 0, 0, 0, 0, time.UTC))
+	sent := make(chan reminded, 1)
+	s, err := NewScheduler(senderFunc(func(e core.Event, b core.Booking, before time.Duration) error {
+		sent <- reminded{BookingID: b.ID, Before: before}
+		return nil
+	}), &MemoryStore{}, time.Hour)
+	assert.NoError(t, err)
+	s.Clock = clock
+
+	e := coretest.NewEvent(time.Hour)
+	e.Clock = clock
+	s.Watch(e)
+	b, err := e.CreateBooking(core.CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx, time.Minute)
+	clock.BlockUntil(1)
+
+	clock.Set(time.Date(2022, 2, 7, 7, 59, 0, 0, time.UTC))
+	select {
+	case r := <-sent:
+		t.Fatalf("reminder sent too early: %v", r)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// a tick is dropped if Run is still busy with the previous one, like
+	// time.Ticker, so keep ticking within the hour before the meeting
+	for i := 0; i < 30; i++ {
+		clock.Advance(time.Minute)
+		select {
+		case r := <-sent:
+			assert.Equal(t, reminded{BookingID: b.ID, Before: time.Hour}, r)
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	t.Fatal("reminder not sent")
+}
@@ -0,0 +1,77 @@
+package reminder
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the pending jobs so that reminders survive restarts
+type Store interface {
+	Load() ([]Job, error)
+	Save(jobs []Job) error
+}
+
+// FileStore keeps the jobs in a JSON file
+type FileStore struct {
+	Path string
+}
+
+// Load returns the saved jobs. A missing file has no jobs
+func (s FileStore) Load() ([]Job, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	if err := json.Unmarshal(b, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Save replaces the file atomically
+func (s FileStore) Save(jobs []Job) error {
+	b, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// MemoryStore keeps the jobs in memory
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs []Job
+}
+
+func (s *MemoryStore) Load() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Job(nil), s.jobs...), nil
+}
+
+func (s *MemoryStore) Save(jobs []Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append([]Job(nil), jobs...)
+	return nil
+}
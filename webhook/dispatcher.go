@@ -0,0 +1,459 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+const (
+	defaultMaxAttempts = 8
+	defaultBackoff     = 30 * time.Second
+	defaultMaxBackoff  = time.Hour
+
+	// maxResponseLog is how much of the response body is kept in the log
+	maxResponseLog = 512
+
+	DefaultTimeout = 10 * time.Second
+)
+
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+var (
+	ErrInvalidSubscription  = fmt.Errorf("invalid webhook subscription")
+	ErrSubscriptionNotFound = fmt.Errorf("webhook subscription not found")
+	ErrDeliveryNotFound     = fmt.Errorf("webhook delivery not found")
+	ErrDeliveryNotDead      = fmt.Errorf("webhook delivery is not dead lettered")
+)
+
+// Subscription receives the booking changes at its URL
+type Subscription struct {
+	ID  uuid.UUID
+	URL string
+
+	// Secret signs the requests, see Sign
+	Secret string
+
+	// Types are the delivered event types, e.g. booking.created. Empty
+	// delivers every type
+	Types []string
+}
+
+// Matches returns true if the event type is delivered to the subscription
+func (s Subscription) Matches(typ string) bool {
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus int
+
+const (
+	// DeliveryPending is waiting for its next attempt
+	DeliveryPending DeliveryStatus = iota
+	DeliverySucceeded
+	// DeliveryDeadLettered failed every attempt. It is kept until it is
+	// redelivered or its subscription is removed
+	DeliveryDeadLettered
+)
+
+func (s DeliveryStatus) String() string {
+	switch s {
+	case DeliveryPending:
+		return "pending"
+	case DeliverySucceeded:
+		return "succeeded"
+	case DeliveryDeadLettered:
+		return "dead lettered"
+	default:
+		return "unknown"
+	}
+}
+
+// Attempt is a request sent for a delivery
+type Attempt struct {
+	At       time.Time
+	Duration time.Duration
+
+	// StatusCode is zero if no response was received
+	StatusCode int
+
+	// Response is the beginning of the response body
+	Response string
+	Error    string
+}
+
+// Delivery is a payload sent to a subscription
+type Delivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	Type           string
+	Body           []byte
+	CreatedAt      time.Time
+
+	Status   DeliveryStatus
+	Attempts []Attempt
+
+	// NextAttemptAt is when a pending delivery is sent
+	NextAttemptAt time.Time
+
+	// tries counts the attempts since the delivery was queued or
+	// redelivered
+	tries int
+}
+
+// Dispatcher delivers the booking changes to the subscriptions. Failed
+// deliveries are retried with exponential backoff and dead lettered after
+// MaxAttempts. Every delivery is kept in the log of its subscription.
+// The zero value is ready to use
+type Dispatcher struct {
+	// Client sends the deliveries. Requests time out after DefaultTimeout
+	// if it is nil
+	Client *http.Client
+
+	// MaxAttempts is how many times a delivery is tried. Zero means 8
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. It doubles on every
+	// retry up to MaxBackoff. Zero means 30s and 1h
+	Backoff, MaxBackoff time.Duration
+
+	// OnError is called with errors of Run
+	OnError func(err error)
+
+	// Clock ticks Run and times the requests. SystemClock is used if it is
+	// nil
+	Clock core.Clock
+
+	mu            sync.Mutex
+	subscriptions []Subscription
+	deliveries    []Delivery
+
+	// running prevents concurrent runs from sending the same delivery
+	running sync.Mutex
+}
+
+// Subscribe adds a subscription for the given event types. No type
+// subscribes to every type
+func (d *Dispatcher) Subscribe(rawURL, secret string, types ...string) (Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("%w: url must be an absolute http url", ErrInvalidSubscription)
+	}
+	if secret == "" {
+		return Subscription{}, fmt.Errorf("%w: secret is required", ErrInvalidSubscription)
+	}
+
+	s := Subscription{
+		ID:     uuid.New(),
+		URL:    rawURL,
+		Secret: secret,
+		Types:  append([]string(nil), types...),
+	}
+	d.mu.Lock()
+	d.subscriptions = append(d.subscriptions, s)
+	d.mu.Unlock()
+	return s, nil
+}
+
+// Unsubscribe removes the subscription along with its deliveries
+func (d *Dispatcher) Unsubscribe(id uuid.UUID) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.findSubscription(id)
+	if i < 0 {
+		return ErrSubscriptionNotFound
+	}
+	d.subscriptions = append(d.subscriptions[:i], d.subscriptions[i+1:]...)
+
+	deliveries := d.deliveries[:0]
+	for _, dl := range d.deliveries {
+		if dl.SubscriptionID != id {
+			deliveries = append(deliveries, dl)
+		}
+	}
+	d.deliveries = deliveries
+	return nil
+}
+
+// Subscriptions returns every subscription
+func (d *Dispatcher) Subscriptions() []Subscription {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Subscription(nil), d.subscriptions...)
+}
+
+// Hook returns the booking hook queueing the change for the subscriptions
+func (d *Dispatcher) Hook() core.BookingHook {
+	return func(e *core.Event, c core.BookingChange) {
+		if err := d.Enqueue(*e, c, e.Now()); err != nil && d.OnError != nil {
+			d.OnError(err)
+		}
+	}
+}
+
+// Handler returns the bus handler queueing the booking changes for the
+// subscriptions, e.g. bus.Subscribe("webhook", "", d.Handler(events))
+func (d *Dispatcher) Handler(events eventbus.Events) eventbus.Handler {
+	return eventbus.BookingHandler(events, func(e *core.Event, c core.BookingChange) error {
+		return d.Enqueue(*e, c, e.Now())
+	})
+}
+
+// Enqueue queues a delivery of the change for every matching subscription.
+// They are sent by the next run
+func (d *Dispatcher) Enqueue(e core.Event, c core.BookingChange, now time.Time) error {
+	p := NewPayload(e, c, now)
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range d.subscriptions {
+		if !s.Matches(p.Type) {
+			continue
+		}
+		d.deliveries = append(d.deliveries, Delivery{
+			ID:             uuid.New(),
+			SubscriptionID: s.ID,
+			Type:           p.Type,
+			Body:           body,
+			CreatedAt:      now,
+			NextAttemptAt:  now,
+		})
+	}
+	return nil
+}
+
+// Deliveries returns the delivery log of the subscription, oldest first
+func (d *Dispatcher) Deliveries(subscriptionID uuid.UUID) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deliveries []Delivery
+	for _, dl := range d.deliveries {
+		if dl.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, copyDelivery(dl))
+		}
+	}
+	return deliveries
+}
+
+// DeadLetters returns the deliveries which failed every attempt
+func (d *Dispatcher) DeadLetters() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var deliveries []Delivery
+	for _, dl := range d.deliveries {
+		if dl.Status == DeliveryDeadLettered {
+			deliveries = append(deliveries, copyDelivery(dl))
+		}
+	}
+	return deliveries
+}
+
+// Redeliver queues a dead lettered delivery again with fresh attempts, e.g.
+// once the receiver is fixed
+func (d *Dispatcher) Redeliver(id uuid.UUID, now time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.findDelivery(id)
+	if i < 0 {
+		return ErrDeliveryNotFound
+	}
+	if d.deliveries[i].Status != DeliveryDeadLettered {
+		return ErrDeliveryNotDead
+	}
+	d.deliveries[i].Status = DeliveryPending
+	d.deliveries[i].NextAttemptAt = now
+	d.deliveries[i].tries = 0
+	return nil
+}
+
+// RunDue sends the deliveries due at now. Deliveries not sent before the
+// context is done are left for the next run
+func (d *Dispatcher) RunDue(ctx context.Context, now time.Time) error {
+	d.running.Lock()
+	defer d.running.Unlock()
+
+	// the requests are sent without holding the lock so that bookings are
+	// not blocked by slow receivers
+	d.mu.Lock()
+	type job struct {
+		delivery     Delivery
+		subscription Subscription
+	}
+	var due []job
+	for _, dl := range d.deliveries {
+		if dl.Status != DeliveryPending || dl.NextAttemptAt.After(now) {
+			continue
+		}
+		if i := d.findSubscription(dl.SubscriptionID); i >= 0 {
+			due = append(due, job{delivery: dl, subscription: d.subscriptions[i]})
+		}
+	}
+	d.mu.Unlock()
+
+	var firstErr error
+	for _, j := range due {
+		attempt := d.send(ctx, j.subscription, j.delivery, now)
+		if ctx.Err() != nil {
+			// an aborted request does not count as an attempt
+			return ctx.Err()
+		}
+		if attempt.Error != "" && firstErr == nil {
+			firstErr = fmt.Errorf("deliver %s to %s: %s", j.delivery.Type, j.subscription.URL, attempt.Error)
+		}
+
+		d.mu.Lock()
+		if i := d.findDelivery(j.delivery.ID); i >= 0 {
+			d.record(&d.deliveries[i], attempt, now)
+		}
+		d.mu.Unlock()
+	}
+	return firstErr
+}
+
+// Run calls RunDue every interval until the context is done
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := d.clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C():
+			if err := d.RunDue(ctx, now); err != nil && d.OnError != nil {
+				d.OnError(err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, s Subscription, dl Delivery, now time.Time) Attempt {
+	attempt := Attempt{At: now}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(dl.Body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", dl.ID.String())
+	req.Header.Set("X-Webhook-Event", dl.Type)
+	req.Header.Set(SignatureHeader, Sign(s.Secret, now, dl.Body))
+
+	client := d.Client
+	if client == nil {
+		client = defaultClient
+	}
+	start := d.clock().Now()
+	resp, err := client.Do(req)
+	attempt.Duration = d.clock().Now().Sub(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseLog))
+	attempt.StatusCode = resp.StatusCode
+	attempt.Response = strings.TrimSpace(string(msg))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = "receiver responded " + resp.Status
+	}
+	return attempt
+}
+
+// record adds the attempt to the delivery and schedules its retry
+func (d *Dispatcher) record(dl *Delivery, attempt Attempt, now time.Time) {
+	dl.Attempts = append(dl.Attempts, attempt)
+	dl.tries++
+	switch {
+	case attempt.Error == "":
+		dl.Status = DeliverySucceeded
+	case dl.tries >= d.maxAttempts():
+		dl.Status = DeliveryDeadLettered
+	default:
+		dl.NextAttemptAt = now.Add(d.backoff(dl.tries))
+	}
+}
+
+// backoff returns the delay after the given number of failed tries
+func (d *Dispatcher) backoff(tries int) time.Duration {
+	delay, max := d.Backoff, d.MaxBackoff
+	if delay <= 0 {
+		delay = defaultBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	for i := 1; i < tries && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+func (d *Dispatcher) clock() core.Clock {
+	if d.Clock == nil {
+		return core.SystemClock
+	}
+	return d.Clock
+}
+
+func (d *Dispatcher) maxAttempts() int {
+	if d.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return d.MaxAttempts
+}
+
+func (d *Dispatcher) findSubscription(id uuid.UUID) int {
+	for i, s := range d.subscriptions {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Dispatcher) findDelivery(id uuid.UUID) int {
+	for i, dl := range d.deliveries {
+		if dl.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func copyDelivery(dl Delivery) Delivery {
+	dl.Attempts = append([]Attempt(nil), dl.Attempts...)
+	return dl
+}
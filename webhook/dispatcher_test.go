@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/imrenagi/calendly-demo/core"
+	"github.com/imrenagi/calendly-demo/core/coretest"
+	"github.com/imrenagi/calendly-demo/eventbus"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// fakeReceiver records the webhook requests and responds with the status
+// codes in order, then with 200
+type fakeReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []received
+	statuses []int
+}
+
+func newFakeReceiver(t *testing.T, statuses ...int) *fakeReceiver {
+	r := &fakeReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, received{header: req.Header, body: body})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeReceiver) Requests() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.requests...)
+}
+
+func TestDispatcher(t *testing.T) {
+	crm := newFakeReceiver(t)
+	audit := newFakeReceiver(t)
+	d := &Dispatcher{}
+	crmSub, err := d.Subscribe(crm.URL, "crm-secret")
+	assert.NoError(t, err)
+	auditSub, err := d.Subscribe(audit.URL, "audit-secret", "booking.cancelled")
+	assert.NoError(t, err)
+	e := coretest.NewEvent(30 * time.Minute)
+	e.OnBookingChange = d.Hook()
+
+	slot := time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)
+	b, err := e.CreateBooking(core.CreateBookingParameters{
+		Invitee:   core.Invitee{Name: "Bar", Email: "bar@foo.com"},
+		StartTime: slot,
+	})
+	assert.NoError(t, err)
+	_, err = e.RescheduleBooking(b.ID, slot.Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = e.CancelBooking(b.ID)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	assert.NoError(t, d.RunDue(context.Background(), now))
+
+	requests := crm.Requests()
+	assert.Len(t, requests, 3)
+	var types []string
+	for _, r := range requests {
+		types = append(types, r.header.Get("X-Webhook-Event"))
+		assert.NoError(t, Verify("crm-secret", r.header.Get(SignatureHeader), r.body, time.Minute, now))
+	}
+	assert.Equal(t, []string{"booking.created", "booking.rescheduled", "booking.cancelled"}, types)
+
+	var p Payload
+	assert.NoError(t, json.Unmarshal(requests[1].body, &p))
+	assert.Equal(t, "booking.rescheduled", p.Type)
+	assert.Equal(t, e.ID, p.Data.EventID)
+	assert.Equal(t, b.ID, p.Data.Booking.ID)
+	assert.Equal(t, "bar@foo.com", p.Data.Booking.Invitee.Email)
+	assert.Equal(t, slot.Add(time.Hour), p.Data.Booking.StartTime)
+	assert.Equal(t, slot.Add(90*time.Minute), p.Data.Booking.EndTime)
+	assert.Equal(t, slot, p.Data.Previous.StartTime)
+
+	assert.Len(t, audit.Requests(), 1)
+	assert.Equal(t, "booking.cancelled", audit.Requests()[0].header.Get("X-Webhook-Event"))
+
+	log := d.Deliveries(crmSub.ID)
+	assert.Len(t, log, 3)
+	for _, dl := range log {
+		assert.Equal(t, DeliverySucceeded, dl.Status)
+		assert.Len(t, dl.Attempts, 1)
+		assert.Equal(t, http.StatusOK, dl.Attempts[0].StatusCode)
+	}
+
+	// nothing is sent twice
+	assert.NoError(t, d.RunDue(context.Background(), now.Add(time.Hour)))
+	assert.Len(t, crm.Requests(), 3)
+
+	assert.NoError(t, d.Unsubscribe(auditSub.ID))
+	assert.Empty(t, d.Deliveries(auditSub.ID))
+	assert.Equal(t, ErrSubscriptionNotFound, d.Unsubscribe(auditSub.ID))
+}
+
+func TestDispatcher_Handler(t *testing.T) {
+	crm := newFakeReceiver(t)
+	d := &Dispatcher{}
+	sub, err := d.Subscribe(crm.URL, "crm-secret")
+	assert.NoError(t, err)
+	e := coretest.NewEvent(30 * time.Minute)
+	e.OnBookingChange = d.Hook()
+	e.OnBookingChange = nil
+	bus := eventbus.New(&eventbus.MemoryOutbox{})
+	bus.Subscribe("webhook", "", d.Handler(func(id uuid.UUID) *core.Event { return e }))
+	e.Publisher = bus
+
+	_, err = e.CreateBooking(core.CreateBookingParameters{StartTime: time.Date(2022, 2, 7, 9, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	e.Update(func(e *core.Event) { e.Name = "renamed" })
+
+	log := d.Deliveries(sub.ID)
+	assert.Len(t, log, 1)
+	assert.Equal(t, "booking.created", log[0].Type)
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	crm := newFakeReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusInternalServerError)
+	d := &Dispatcher{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: 90 * time.Second}
+	sub, err := d.Subscribe(crm.URL, "secret")
+	assert.NoError(t, err)
+
+	now := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, d.Enqueue(core.Event{}, core.BookingChange{Kind: core.ChangeCreated}, now))
+
+	assert.Error(t, d.RunDue(context.Background(), now))
+	dl := d.Deliveries(sub.ID)[0]
+	assert.Equal(t, DeliveryPending, dl.Status)
+	assert.Equal(t, now.Add(time.Minute), dl.NextAttemptAt)
+	assert.Equal(t, http.StatusInternalServerError, dl.Attempts[0].StatusCode)
+	assert.Equal(t, "ok", dl.Attempts[0].Response)
+
+	// not due yet
+	assert.NoError(t, d.RunDue(context.Background(), now.Add(30*time.Second)))
+	assert.Len(t, crm.Requests(), 1)
+
+	now = now.Add(time.Minute)
+	assert.Error(t, d.RunDue(context.Background(), now))
+	dl = d.Deliveries(sub.ID)[0]
+	assert.Equal(t, now.Add(90*time.Second), dl.NextAttemptAt, "backoff should be capped")
+
+	now = now.Add(90 * time.Second)
+	assert.Error(t, d.RunDue(context.Background(), now))
+	dl = d.Deliveries(sub.ID)[0]
+	assert.Equal(t, DeliveryDeadLettered, dl.Status)
+	assert.Len(t, dl.Attempts, 3)
+	assert.Equal(t, []Delivery{dl}, d.DeadLetters())
+
+	assert.NoError(t, d.RunDue(context.Background(), now.Add(time.Hour)))
+	assert.Len(t, crm.Requests(), 3)
+
+	// redelivered dead letters get fresh attempts
+	assert.NoError(t, d.Redeliver(dl.ID, now))
+	assert.Equal(t, ErrDeliveryNotDead, d.Redeliver(dl.ID, now))
+	assert.Error(t, d.RunDue(context.Background(), now))
+	assert.NoError(t, d.RunDue(context.Background(), now.Add(time.Minute)))
+	dl = d.Deliveries(sub.ID)[0]
+	assert.Equal(t, DeliverySucceeded, dl.Status)
+	assert.Len(t, dl.Attempts, 5)
+	assert.Empty(t, d.DeadLetters())
+}
+
+func TestDispatcher_RunDue_Cancelled(t *testing.T) {
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	t.Cleanup(hung.Close)
+	t.Cleanup(func() { close(release) })
+
+	d := &Dispatcher{}
+	sub, err := d.Subscribe(hung.URL, "secret")
+	assert.NoError(t, err)
+	now := time.Date(2022, 2, 7, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, d.Enqueue(core.Event{}, core.BookingChange{Kind: core.ChangeCreated}, now))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, d.RunDue(ctx, now))
+
+	dl := d.Deliveries(sub.ID)[0]
+	assert.Equal(t, DeliveryPending, dl.Status)
+	assert.Empty(t, dl.Attempts)
+}
+
+func TestDispatcher_Subscribe(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		secret  string
+		wantErr bool
+	}{
+		{name: "should accept https url", url: "https://crm.example.com/hooks", secret: "s"},
+		{name: "should reject relative url", url: "/hooks", secret: "s", wantErr: true},
+		{name: "should reject other scheme", url: "ftp://crm.example.com", secret: "s", wantErr: true},
+		{name: "should require secret", url: "https://crm.example.com/hooks", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Dispatcher{}
+			_, err := d.Subscribe(tt.url, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Subscribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
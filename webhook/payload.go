@@ -0,0 +1,97 @@
+// Package webhook delivers booking changes to subscribed URLs
+package webhook
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/imrenagi/calendly-demo/core"
+)
+
+// Type returns the webhook event type of the change, e.g. booking.created
+func Type(kind core.ChangeKind) string {
+	return "booking." + kind.String()
+}
+
+// Payload is the JSON body of a webhook request
+type Payload struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      Data      `json:"data"`
+}
+
+// Data describes the changed booking
+type Data struct {
+	EventID   uuid.UUID `json:"event_id"`
+	EventName string    `json:"event_name"`
+	Booking   Booking   `json:"booking"`
+
+	// Previous is the booking before the change. It is omitted for created
+	// bookings
+	Previous *Booking `json:"previous,omitempty"`
+}
+
+type Booking struct {
+	ID              uuid.UUID           `json:"id"`
+	Status          string              `json:"status"`
+	StartTime       time.Time           `json:"start_time"`
+	EndTime         time.Time           `json:"end_time"`
+	Invitee         Invitee             `json:"invitee"`
+	Answers         map[string][]string `json:"answers,omitempty"`
+	MeetingLocation string              `json:"meeting_location,omitempty"`
+	JoinURL         string              `json:"join_url,omitempty"`
+	HostID          *uuid.UUID          `json:"host_id,omitempty"`
+	SeriesID        *uuid.UUID          `json:"series_id,omitempty"`
+	Sequence        int                 `json:"sequence"`
+}
+
+type Invitee struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// NewPayload returns the payload of the booking change
+func NewPayload(e core.Event, c core.BookingChange, now time.Time) Payload {
+	p := Payload{
+		ID:        uuid.New(),
+		Type:      Type(c.Kind),
+		CreatedAt: now.UTC(),
+		Data: Data{
+			EventID:   e.ID,
+			EventName: e.Name,
+			Booking:   newBooking(e, c.Booking),
+		},
+	}
+	if c.Kind != core.ChangeCreated {
+		previous := newBooking(e, c.Previous)
+		p.Data.Previous = &previous
+	}
+	return p
+}
+
+func newBooking(e core.Event, b core.Booking) Booking {
+	p := Booking{
+		ID:        b.ID,
+		Status:    b.Status.String(),
+		StartTime: b.StartTime.UTC(),
+		EndTime:   b.StartTime.Add(e.Duration).UTC(),
+		Invitee:   Invitee{Name: b.Invitee.Name, Email: b.Invitee.Email},
+		Answers:   b.Answers,
+		JoinURL:   b.Conference.JoinURL,
+		Sequence:  b.Sequence,
+	}
+	if !b.MeetingLocation.IsZero() {
+		p.MeetingLocation = b.MeetingLocation.String()
+	}
+	if b.HostID != uuid.Nil {
+		id := b.HostID
+		p.HostID = &id
+	}
+	if b.SeriesID != uuid.Nil {
+		id := b.SeriesID
+		p.SeriesID = &id
+	}
+	return p
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the signature of the request body
+const SignatureHeader = "X-Webhook-Signature"
+
+var (
+	ErrInvalidSignature = fmt.Errorf("invalid webhook signature")
+	ErrSignatureExpired = fmt.Errorf("webhook signature expired")
+)
+
+// Sign returns the signature header value of the body, e.g.
+// t=1644199200,v1=5257a869... where v1 is the hex encoded HMAC-SHA256 of
+// "{t}.{body}" with the subscription's secret
+func Sign(secret string, at time.Time, body []byte) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, body)
+}
+
+// Verify checks the signature header of a received body. Signatures older
+// than tolerance are rejected to prevent replays. Zero tolerance accepts
+// any age
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			t = kv[1]
+		case "v1":
+			signatures = append(signatures, kv[1])
+		}
+	}
+	ts, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	want := signature(secret, t, body)
+	valid := false
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(want)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(ts, 0)) > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func signature(secret, t string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	at := time.Date(2022, 2, 7, 2, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"booking.created"}`)
+	header := Sign("secret", at, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:   "should accept a valid signature",
+			secret: "secret",
+			header: header,
+			body:   body,
+			now:    at.Add(time.Minute),
+		},
+		{
+			name:   "should accept any of the signatures",
+			secret: "secret",
+			header: header + ",v1=deadbeef",
+			body:   body,
+			now:    at,
+		},
+		{
+			name:    "should reject another secret",
+			secret:  "other",
+			header:  header,
+			body:    body,
+			now:     at,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "should reject a changed body",
+			secret:  "secret",
+			header:  header,
+			body:    []byte(`{"type":"booking.cancelled"}`),
+			now:     at,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "should reject a header without timestamp",
+			secret:  "secret",
+			header:  header[len("t=1644199200,"):],
+			body:    body,
+			now:     at,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "should reject an old signature",
+			secret:  "secret",
+			header:  header,
+			body:    body,
+			now:     at.Add(10 * time.Minute),
+			wantErr: ErrSignatureExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}